@@ -0,0 +1,331 @@
+package fsm
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// AlphabetMode is how a machine splits its input into symbols
+type AlphabetMode int
+
+const (
+	// RuneMode machines decode their input as UTF-8, and each rune is a symbol. Invalid UTF-8 is rejected.
+	RuneMode AlphabetMode = iota
+	// ByteMode machines read their input as raw bytes, and each byte is a symbol, with the value of the byte as its rune.
+	ByteMode
+)
+
+func (m AlphabetMode) String() string {
+	switch m {
+	case RuneMode:
+		return "rune"
+	case ByteMode:
+		return "byte"
+	}
+	return fmt.Sprintf("AlphabetMode(%d)", int(m))
+}
+
+// ByteTransition is a Transition for a ByteMode machine
+type ByteTransition struct {
+	State       string
+	Input       byte
+	ResultState string
+}
+
+// NewByteConfig builds and validates a config for a machine that reads raw bytes rather than UTF-8 runes.
+func NewByteConfig(states []string, alphabet []byte, initialState string, finalStates []string, transitions []ByteTransition) (*Config, error) {
+	runes := make([]rune, len(alphabet))
+	for i, input := range alphabet {
+		runes[i] = rune(input)
+	}
+
+	runeTransitions := make([]Transition, len(transitions))
+	for i, transition := range transitions {
+		runeTransitions[i] = Transition{State: transition.State, Input: rune(transition.Input), ResultState: transition.ResultState}
+	}
+
+	conf, err := NewConfig(states, runes, initialState, finalStates, runeTransitions)
+	if err != nil {
+		return nil, err
+	}
+	conf.mode = ByteMode
+	return conf, nil
+}
+
+// Mode returns how the machine splits its input into symbols
+func (c *Config) Mode() AlphabetMode {
+	return c.mode
+}
+
+// decode returns the symbol at position i of the input, and how many bytes it takes up.
+// In RuneMode, invalid UTF-8 is an error rather than U+FFFD, so it can't be mistaken for a genuine U+FFFD.
+func (c *Config) decode(input string, i int) (rune, int, error) {
+	if c.mode == ByteMode {
+		return rune(input[i]), 1, nil
+	}
+
+	symbol, size := utf8.DecodeRuneInString(input[i:])
+	if symbol == utf8.RuneError && size <= 1 {
+		return 0, 1, fmt.Errorf("%w at byte %d", ErrInvalidUTF8, i)
+	}
+	return symbol, size, nil
+}
+
+// encode appends a symbol to an input being built up, as UTF-8 in RuneMode and as a single byte in ByteMode
+func (c *Config) encode(builder *strings.Builder, symbol rune) {
+	if c.mode == ByteMode {
+		builder.WriteByte(byte(symbol))
+		return
+	}
+	builder.WriteRune(symbol)
+}
+
+// symbolString returns the input made of just the symbol
+func (c *Config) symbolString(symbol rune) string {
+	var builder strings.Builder
+	c.encode(&builder, symbol)
+	return builder.String()
+}
+
+// symbolsOf returns the symbols of an input built up with encode
+func (c *Config) symbolsOf(input string) []rune {
+	if c.mode == ByteMode {
+		symbols := make([]rune, len(input))
+		for i := range len(input) {
+			symbols[i] = rune(input[i])
+		}
+		return symbols
+	}
+	return []rune(input)
+}
+
+// inputOf returns the input made of the symbols in order
+func (c *Config) inputOf(symbols []rune) string {
+	var builder strings.Builder
+	for _, symbol := range symbols {
+		c.encode(&builder, symbol)
+	}
+	return builder.String()
+}
+
+// Evaluate runs the machine over the input like Process, but says why an input is rejected:
+// ErrEmptyInput, ErrInvalidUTF8, ErrInvalidInput, ErrMissingTransition, or ErrNotAccepted.
+func (f *FiniteStateMachine) Evaluate(input string) (string, error) {
+	if len(input) == 0 {
+		return "", ErrEmptyInput
+	}
+
+	run := f.NewRun()
+	if err := run.Feed(input); err != nil {
+		return "", err
+	}
+	if !run.Accepted() {
+		return "", fmt.Errorf("%w: ended in state %s", ErrNotAccepted, run.State())
+	}
+	return run.State(), nil
+}
+
+// ProcessBytes is Process for input held as bytes.
+// A ByteMode machine reads each byte as a symbol, and a RuneMode machine decodes the bytes as UTF-8.
+func (f *FiniteStateMachine) ProcessBytes(input []byte) (*string, bool) {
+	return f.Process(string(input))
+}
+
+// EvaluateBytes is Evaluate for input held as bytes
+func (f *FiniteStateMachine) EvaluateBytes(input []byte) (string, error) {
+	return f.Evaluate(string(input))
+}
+
+// ToByteConfig compiles a RuneMode config into an equivalent ByteMode config, which accepts exactly the UTF-8
+// encodings of the inputs the original accepts, and rejects everything else, including invalid UTF-8.
+//
+// Every original state is kept, and extra states are added for being part way through a multi-byte rune.
+// Wherever a stretch of runes all lead to the same state, the bytes left to read are checked only for being
+// continuation bytes, so those extra states are shared and stay few even for alphabets like unicode.Letter.
+func (c *Config) ToByteConfig() (*Config, error) {
+	if c.mode == ByteMode {
+		copied := *c
+		return &copied, nil
+	}
+
+	compiler := newByteCompiler(c)
+	for _, state := range c.Transitions.sortedStates() {
+		compiler.compileState(state)
+	}
+
+	conf, err := newConfig(compiler.states, nil, []RuneRange{{0, 0xFF}}, c.initialState, c.FinalStates(), nil, compiler.transitions)
+	if err != nil {
+		return nil, err
+	}
+	conf.mode = ByteMode
+	return conf, nil
+}
+
+// The code points that can be written with each length of UTF-8 sequence
+var utf8Lengths = []struct {
+	lead     RuneRange
+	min, max rune
+}{
+	{lead: RuneRange{0x00, 0x7F}, min: 0, max: 0x7F},
+	{lead: RuneRange{0xC0, 0xDF}, min: 0x80, max: 0x7FF},
+	{lead: RuneRange{0xE0, 0xEF}, min: 0x800, max: 0xFFFF},
+	{lead: RuneRange{0xF0, 0xF7}, min: 0x10000, max: utf8.MaxRune},
+}
+
+type byteCompiler struct {
+	config      *Config
+	classes     []RuneRange
+	states      []string
+	stateSet    map[string]struct{}
+	transitions []RangeTransition
+	// reject is the state for invalid UTF-8 and runes the original has no transition for
+	reject string
+	// continuations holds the shared states that read a number of continuation bytes and then go to a state
+	continuations map[string]string
+}
+
+func newByteCompiler(config *Config) *byteCompiler {
+	compiler := &byteCompiler{
+		config:        config,
+		classes:       config.Transitions.classes(),
+		stateSet:      make(map[string]struct{}),
+		continuations: make(map[string]string),
+	}
+	for _, state := range config.Transitions.sortedStates() {
+		compiler.addState(state)
+	}
+	compiler.reject = compiler.newState("utf8:reject")
+	compiler.add(compiler.reject, RuneRange{0, 0xFF}, compiler.reject)
+	return compiler
+}
+
+// newState adds a state named after base, changing the name if needed so it can't clash with an original state
+func (b *byteCompiler) newState(base string) string {
+	name := base
+	for {
+		if _, taken := b.stateSet[name]; !taken {
+			break
+		}
+		name += "'"
+	}
+	b.addState(name)
+	return name
+}
+
+func (b *byteCompiler) addState(state string) {
+	b.stateSet[state] = struct{}{}
+	b.states = append(b.states, state)
+}
+
+func (b *byteCompiler) add(state string, inputs RuneRange, next string) {
+	b.transitions = append(b.transitions, RangeTransition{State: state, Inputs: []RuneRange{inputs}, ResultState: next})
+}
+
+// target returns where the original machine goes from state on the rune, or the reject state
+func (b *byteCompiler) target(state string, input rune) string {
+	if input >= 0xD800 && input <= 0xDFFF {
+		// surrogates aren't valid in UTF-8
+		return b.reject
+	}
+	if next, ok := b.config.Next(state, input); ok {
+		return next
+	}
+	return b.reject
+}
+
+// uniformTarget returns the single state every rune from lo to hi leads to from state, and false if they don't all agree
+func (b *byteCompiler) uniformTarget(state string, lo rune, hi rune) (string, bool) {
+	// the original machine treats whole classes the same, so one rune per class covers the range
+	var target string
+	checked := false
+	check := func(input rune) bool {
+		next := b.target(state, input)
+		if checked && next != target {
+			return false
+		}
+		target, checked = next, true
+		return true
+	}
+
+	if !check(lo) {
+		return "", false
+	}
+	for _, boundary := range []rune{0xD800, 0xE000} {
+		if lo < boundary && boundary <= hi && !check(boundary) {
+			return "", false
+		}
+	}
+	classes := b.classes
+	first := sort.Search(len(classes), func(i int) bool { return classes[i].Hi >= lo })
+	for _, class := range classes[first:] {
+		if class.Lo > hi {
+			break
+		}
+		if !check(max(class.Lo, lo)) || (class.Hi < hi && !check(class.Hi+1)) {
+			return "", false
+		}
+	}
+	return target, true
+}
+
+// continuation returns the shared state that reads count continuation bytes and then goes to next
+func (b *byteCompiler) continuation(count int, next string) string {
+	if count == 0 || next == b.reject {
+		return next
+	}
+
+	key := fmt.Sprintf("%d %s", count, next)
+	if state, ok := b.continuations[key]; ok {
+		return state
+	}
+	state := b.newState(fmt.Sprintf("utf8:%s+%d", next, count))
+	b.continuations[key] = state
+	b.add(state, RuneRange{0x80, 0xBF}, b.continuation(count-1, next))
+	b.add(state, RuneRange{0x00, 0x7F}, b.reject)
+	b.add(state, RuneRange{0xC0, 0xFF}, b.reject)
+	return state
+}
+
+func (b *byteCompiler) compileState(state string) {
+	for length, encoding := range utf8Lengths {
+		for lead := encoding.lead.Lo; lead <= encoding.lead.Hi; lead++ {
+			// the code points starting with this lead byte, some of which may be overlong or out of range
+			shift := 6 * length
+			payload := lead & (0x7F >> (length + min(length, 1)))
+			lo := payload << shift
+			hi := lo | (1<<shift - 1)
+			b.add(state, Single(lead), b.subtree(state, fmt.Sprintf("%x", lead), lo, hi, length, encoding.min, encoding.max))
+		}
+	}
+	// no UTF-8 sequence starts with a continuation byte, or with these
+	b.add(state, RuneRange{0x80, 0xBF}, b.reject)
+	b.add(state, RuneRange{0xF8, 0xFF}, b.reject)
+}
+
+// subtree returns the state for having read part of a rune from state, where the runes still possible are lo to hi
+// with remaining continuation bytes left to read. Runes outside min to max would be overlong or out of range.
+func (b *byteCompiler) subtree(state string, prefix string, lo rune, hi rune, remaining int, minimum rune, maximum rune) string {
+	if hi < minimum || lo > maximum {
+		return b.reject
+	}
+	if lo >= minimum && hi <= maximum {
+		if target, ok := b.uniformTarget(state, lo, hi); ok {
+			return b.continuation(remaining, target)
+		}
+	}
+
+	// The runes left lead to different places, so this byte needs a state of its own to tell them apart
+	node := b.newState(fmt.Sprintf("utf8:%s/%s", state, prefix))
+	shift := 6 * (remaining - 1)
+	for continuation := rune(0); continuation < 64; continuation++ {
+		childLo := lo | continuation<<shift
+		childHi := childLo | (1<<shift - 1)
+		child := b.subtree(state, fmt.Sprintf("%s%x", prefix, 0x80|continuation), childLo, childHi, remaining-1, minimum, maximum)
+		b.add(node, Single(0x80|continuation), child)
+	}
+	b.add(node, RuneRange{0x00, 0x7F}, b.reject)
+	b.add(node, RuneRange{0xC0, 0xFF}, b.reject)
+	return node
+}
@@ -0,0 +1,212 @@
+package fsm
+
+import (
+	"math/rand"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newByteMod3Config is the mod3 machine accepting binary numbers divisible by three, written as the bytes 0 and 1
+func newByteMod3Config(tb testing.TB) *Config {
+	tb.Helper()
+
+	var transitions []ByteTransition
+	for _, transition := range mod3Transitions {
+		transitions = append(transitions, ByteTransition{State: transition.State, Input: byte(transition.Input - '0'), ResultState: transition.ResultState})
+	}
+	conf, err := NewByteConfig([]string{"S0", "S1", "S2"}, []byte{0, 1}, "S0", []string{"S0"}, transitions)
+	if err != nil {
+		tb.Fatalf("byte config should not have resulted in an error: %s", err)
+	}
+	return conf
+}
+
+func TestByteConfig(t *testing.T) {
+	conf := newByteMod3Config(t)
+	assert.Equal(t, ByteMode, conf.Mode())
+
+	fsm, err := New(*conf)
+	assert.Nil(t, err)
+
+	type test struct {
+		name     string
+		input    []byte
+		expected bool
+	}
+
+	tests := []test{
+		{name: "three", input: []byte{1, 1}, expected: true},
+		{name: "six", input: []byte{1, 1, 0}, expected: true},
+		{name: "five", input: []byte{1, 0, 1}, expected: false},
+		{name: "outside the alphabet", input: []byte{1, 2}, expected: false},
+		{name: "empty", input: nil, expected: false},
+	}
+
+	for _, currentTest := range tests {
+		t.Run(currentTest.name, func(t *testing.T) {
+			_, accepted := fsm.ProcessBytes(currentTest.input)
+			assert.Equal(t, currentTest.expected, accepted)
+		})
+	}
+
+	shortest, ok := conf.ShortestAccepted()
+	assert.True(t, ok)
+	assert.Equal(t, "\x00", shortest)
+
+	rejected, ok := conf.ShortestRejected()
+	assert.True(t, ok)
+	assert.Equal(t, "\x01", rejected)
+}
+
+func TestByteConfigReadsBytesNotRunes(t *testing.T) {
+	// 0xC3 0xA9 is é in UTF-8, but a byte machine sees two separate bytes
+	conf, err := NewByteConfig([]string{"start", "lead", "done"}, []byte{0xC3, 0xA9}, "start", []string{"done"}, []ByteTransition{
+		{State: "start", Input: 0xC3, ResultState: "lead"},
+		{State: "start", Input: 0xA9, ResultState: "start"},
+		{State: "lead", Input: 0xC3, ResultState: "start"},
+		{State: "lead", Input: 0xA9, ResultState: "done"},
+		{State: "done", Input: 0xC3, ResultState: "done"},
+		{State: "done", Input: 0xA9, ResultState: "done"},
+	})
+	assert.Nil(t, err)
+
+	fsm, err := New(*conf)
+	assert.Nil(t, err)
+
+	state, accepted := fsm.Process("é")
+	assert.True(t, accepted)
+	assert.Equal(t, "done", *state)
+
+	// invalid UTF-8 is just more bytes
+	_, accepted = fsm.ProcessBytes([]byte{0xA9, 0xC3, 0xA9})
+	assert.True(t, accepted)
+
+	run := fsm.NewRun()
+	assert.Nil(t, run.Feed("\xC3"))
+	assert.Equal(t, "lead", run.State())
+	assert.Equal(t, 1, run.Position())
+
+	// the fingerprint tells byte machines from rune machines with the same transitions
+	runeConf, err := NewConfig([]string{"start", "lead", "done"}, []rune{0xC3, 0xA9}, "start", []string{"done"}, []Transition{
+		{State: "start", Input: 0xC3, ResultState: "lead"},
+		{State: "start", Input: 0xA9, ResultState: "start"},
+		{State: "lead", Input: 0xC3, ResultState: "start"},
+		{State: "lead", Input: 0xA9, ResultState: "done"},
+		{State: "done", Input: 0xC3, ResultState: "done"},
+		{State: "done", Input: 0xA9, ResultState: "done"},
+	})
+	assert.Nil(t, err)
+	assert.NotEqual(t, runeConf.Fingerprint(), conf.Fingerprint())
+}
+
+func TestEvaluate(t *testing.T) {
+	fsm, err := New(*newIdentifierConfig(t))
+	assert.Nil(t, err)
+
+	type test struct {
+		name          string
+		input         string
+		expectedState string
+		expectedError error
+	}
+
+	tests := []test{
+		{name: "identifier", input: "déjà2vu", expectedState: "ident"},
+		{name: "empty", input: "", expectedError: ErrEmptyInput},
+		{name: "truncated rune", input: "d\xC3", expectedError: ErrInvalidUTF8},
+		{name: "stray continuation byte", input: "\xA9d", expectedError: ErrInvalidUTF8},
+		{name: "encoded surrogate", input: "a\xED\xA0\x80", expectedError: ErrInvalidUTF8},
+		{name: "outside the alphabet", input: "a b", expectedError: ErrInvalidInput},
+		{name: "not accepted", input: "9lives", expectedError: ErrNotAccepted},
+	}
+
+	for _, currentTest := range tests {
+		t.Run(currentTest.name, func(t *testing.T) {
+			state, err := fsm.Evaluate(currentTest.input)
+			assert.ErrorIs(t, err, currentTest.expectedError)
+			assert.Equal(t, currentTest.expectedState, state)
+
+			_, accepted := fsm.Process(currentTest.input)
+			assert.Equal(t, currentTest.expectedError == nil, accepted)
+		})
+	}
+
+	// a genuine U+FFFD is not mistaken for invalid UTF-8
+	_, err = fsm.Evaluate("a�")
+	assert.ErrorIs(t, err, ErrInvalidInput)
+
+	_, err = fsm.EvaluateBytes([]byte("d\xC3"))
+	assert.EqualError(t, err, "position 1: input is not valid UTF-8 at byte 1")
+}
+
+func TestToByteConfig(t *testing.T) {
+	runeConf := newIdentifierConfig(t)
+	byteConf, err := runeConf.ToByteConfig()
+	assert.Nil(t, err)
+	assert.Equal(t, ByteMode, byteConf.Mode())
+
+	runeFSM, err := New(*runeConf)
+	assert.Nil(t, err)
+	byteFSM, err := New(*byteConf)
+	assert.Nil(t, err)
+
+	inputs := []string{
+		"x", "déjà2vu", "Ωmega9", "変数", "𝒳1", "9lives", "snake_it", "a b", "",
+		"d\xC3", "\xA9d", "a\xED\xA0\x80", "a\xC0\xAF", "a\xF4\x90\x80\x80", "a�",
+	}
+	random := rand.New(rand.NewSource(1))
+	for range 2000 {
+		var input []byte
+		for range 1 + random.Intn(6) {
+			switch random.Intn(3) {
+			case 0:
+				input = append(input, byte(random.Intn(256)))
+			case 1:
+				input = utf8.AppendRune(input, rune(random.Intn(0x3000)))
+			default:
+				input = utf8.AppendRune(input, rune(random.Intn(utf8.MaxRune+1)))
+			}
+		}
+		inputs = append(inputs, string(input))
+	}
+
+	for _, input := range inputs {
+		runeState, runeAccepted := runeFSM.Process(input)
+		byteState, byteAccepted := byteFSM.ProcessBytes([]byte(input))
+		assert.Equal(t, runeAccepted, byteAccepted, "%q", input)
+		if runeAccepted && byteAccepted {
+			assert.Equal(t, *runeState, *byteState, "%q", input)
+		}
+	}
+
+	// the stretches of letters that share continuation checks keep the machine small
+	assert.Less(t, len(byteConf.States()), 2000)
+}
+
+func TestToByteConfigStateNames(t *testing.T) {
+	// original states named like the generated ones must not clash with them
+	conf, err := NewConfig([]string{"utf8:reject", "a"}, []rune{'é'}, "a", []string{"utf8:reject"}, []Transition{
+		{State: "a", Input: 'é', ResultState: "utf8:reject"},
+		{State: "utf8:reject", Input: 'é', ResultState: "a"},
+	})
+	assert.Nil(t, err)
+
+	byteConf, err := conf.ToByteConfig()
+	assert.Nil(t, err)
+
+	fsm, err := New(*byteConf)
+	assert.Nil(t, err)
+
+	for input, expected := range map[string]bool{
+		"é":     true,
+		"éé":    false,
+		"ééé":   true,
+		"e":     false,
+		"é\xC3": false,
+	} {
+		_, accepted := fsm.Process(input)
+		assert.Equal(t, expected, accepted, "%q", input)
+	}
+}
@@ -0,0 +1,141 @@
+package fsm
+
+import "fmt"
+
+// Analysis is the result of Config.Analyze.
+// State lists are sorted.
+type Analysis struct {
+	// UnreachableStates can't be reached from the initial state by any input
+	UnreachableStates []string
+	// DeadStates can never lead to a final state, whatever input follows
+	DeadStates []string
+	// EmptyLanguage is true when the machine accepts no input at all
+	EmptyLanguage bool
+	// UniversalLanguage is true when the machine accepts every non-empty input over its alphabet
+	UniversalLanguage bool
+}
+
+// Analyze reports on the structure of the machine described by the config.
+// Process never accepts the empty input, so the empty and universal checks are over non-empty inputs.
+func (c *Config) Analyze() Analysis {
+	analysis := Analysis{}
+	states := c.Transitions.sortedStates()
+	alphabet := c.Transitions.symbols()
+
+	reachable, reachableByInput := c.reachableStates()
+	live := c.liveStates()
+
+	for _, state := range states {
+		if _, ok := reachable[state]; !ok {
+			analysis.UnreachableStates = append(analysis.UnreachableStates, state)
+		}
+		if _, ok := live[state]; !ok {
+			analysis.DeadStates = append(analysis.DeadStates, state)
+		}
+	}
+
+	analysis.EmptyLanguage = true
+	analysis.UniversalLanguage = true
+	for state := range reachableByInput {
+		if _, ok := c.finalStates[state]; ok {
+			analysis.EmptyLanguage = false
+		} else {
+			analysis.UniversalLanguage = false
+		}
+	}
+	// a missing transition from a reachable state rejects some input
+	for state := range reachable {
+		for _, input := range alphabet {
+			if _, ok := c.Transitions.lookup(state, input); !ok {
+				analysis.UniversalLanguage = false
+			}
+		}
+	}
+
+	return analysis
+}
+
+// reachableStates returns the states reachable from the initial state, and the states reachable after at least
+// one character, which are the only ones whose finality matters to Process
+func (c *Config) reachableStates() (map[string]struct{}, map[string]struct{}) {
+	alphabet := c.Transitions.symbols()
+
+	reachable := map[string]struct{}{c.initialState: {}}
+	reachableByInput := make(map[string]struct{})
+	queue := []string{c.initialState}
+	for len(queue) > 0 {
+		state := queue[0]
+		queue = queue[1:]
+		for _, input := range alphabet {
+			next, ok := c.Transitions.lookup(state, input)
+			if !ok {
+				continue
+			}
+			reachableByInput[next] = struct{}{}
+			if _, seen := reachable[next]; !seen {
+				reachable[next] = struct{}{}
+				queue = append(queue, next)
+			}
+		}
+	}
+
+	return reachable, reachableByInput
+}
+
+// liveStates returns the states that can still get to a final state, found by walking backwards from the final states
+func (c *Config) liveStates() map[string]struct{} {
+	states := c.Transitions.sortedStates()
+	alphabet := c.Transitions.symbols()
+
+	reverse := make(map[string][]string)
+	for _, state := range states {
+		for _, input := range alphabet {
+			if next, ok := c.Transitions.lookup(state, input); ok {
+				reverse[next] = append(reverse[next], state)
+			}
+		}
+	}
+
+	live := make(map[string]struct{})
+	var queue []string
+	for _, state := range states {
+		if _, ok := c.finalStates[state]; ok {
+			live[state] = struct{}{}
+			queue = append(queue, state)
+		}
+	}
+	for len(queue) > 0 {
+		state := queue[0]
+		queue = queue[1:]
+		for _, previous := range reverse[state] {
+			if _, seen := live[previous]; !seen {
+				live[previous] = struct{}{}
+				queue = append(queue, previous)
+			}
+		}
+	}
+
+	return live
+}
+
+// ValidateStrict does everything Validate does, and also fails if the machine has
+// unreachable states, dead states, or accepts no input at all.
+func (c *Config) ValidateStrict() error {
+	if err := c.Validate(); err != nil {
+		return err
+	}
+
+	var problems []error
+	analysis := c.Analyze()
+	for _, state := range analysis.UnreachableStates {
+		problems = append(problems, newProblem(ErrUnreachableState, fmt.Sprintf("state %s is unreachable from initial state", state)))
+	}
+	for _, state := range analysis.DeadStates {
+		problems = append(problems, newProblem(ErrDeadState, fmt.Sprintf("state %s can never reach a final state", state)))
+	}
+	if analysis.EmptyLanguage {
+		problems = append(problems, ErrEmptyLanguage)
+	}
+
+	return validationErrors(problems...)
+}
@@ -0,0 +1,110 @@
+package fsm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAnalyze(t *testing.T) {
+	type test struct {
+		name          string
+		states        []string
+		initialState  string
+		finalStates   []string
+		transitions   []Transition
+		expected      Analysis
+		expectedError error
+	}
+
+	tests := []test{
+		{
+			name:         "mod3 - everything accepted",
+			states:       []string{"S0", "S1", "S2"},
+			initialState: "S0",
+			finalStates:  []string{"S0", "S1", "S2"},
+			transitions: []Transition{
+				{State: "S0", Input: 'a', ResultState: "S0"},
+				{State: "S0", Input: 'b', ResultState: "S1"},
+				{State: "S1", Input: 'a', ResultState: "S2"},
+				{State: "S1", Input: 'b', ResultState: "S0"},
+				{State: "S2", Input: 'a', ResultState: "S1"},
+				{State: "S2", Input: 'b', ResultState: "S2"},
+			},
+			expected: Analysis{UniversalLanguage: true},
+		},
+		{
+			name:         "unreachable and dead states",
+			states:       []string{"q0", "q1", "sink", "orphan"},
+			initialState: "q0",
+			finalStates:  []string{"q1"},
+			transitions: []Transition{
+				{State: "q0", Input: 'a', ResultState: "q1"},
+				{State: "q0", Input: 'b', ResultState: "sink"},
+				{State: "q1", Input: 'a', ResultState: "q1"},
+				{State: "q1", Input: 'b', ResultState: "q0"},
+				{State: "sink", Input: 'a', ResultState: "sink"},
+				{State: "sink", Input: 'b', ResultState: "sink"},
+				{State: "orphan", Input: 'a', ResultState: "q0"},
+				{State: "orphan", Input: 'b', ResultState: "sink"},
+			},
+			expected: Analysis{
+				UnreachableStates: []string{"orphan"},
+				DeadStates:        []string{"sink"},
+			},
+			expectedError: ErrUnreachableState,
+		},
+		{
+			name:         "final state only reachable by the empty input",
+			states:       []string{"q0", "q1"},
+			initialState: "q0",
+			finalStates:  []string{"q0"},
+			transitions: []Transition{
+				{State: "q0", Input: 'a', ResultState: "q1"},
+				{State: "q0", Input: 'b', ResultState: "q1"},
+				{State: "q1", Input: 'a', ResultState: "q1"},
+				{State: "q1", Input: 'b', ResultState: "q1"},
+			},
+			expected: Analysis{
+				DeadStates:    []string{"q1"},
+				EmptyLanguage: true,
+			},
+			expectedError: ErrDeadState,
+		},
+		{
+			name:         "final state unreachable",
+			states:       []string{"q0", "q1"},
+			initialState: "q0",
+			finalStates:  []string{"q1"},
+			transitions: []Transition{
+				{State: "q0", Input: 'a', ResultState: "q0"},
+				{State: "q0", Input: 'b', ResultState: "q0"},
+				{State: "q1", Input: 'a', ResultState: "q1"},
+				{State: "q1", Input: 'b', ResultState: "q1"},
+			},
+			expected: Analysis{
+				UnreachableStates: []string{"q1"},
+				DeadStates:        []string{"q0"},
+				EmptyLanguage:     true,
+			},
+			expectedError: ErrUnreachableState,
+		},
+	}
+
+	for _, currentTest := range tests {
+		conf, err := NewConfig(currentTest.states, []rune{'a', 'b'}, currentTest.initialState, currentTest.finalStates, currentTest.transitions)
+		assert.Nil(t, err, currentTest.name)
+		if err != nil {
+			continue
+		}
+
+		assert.Equal(t, currentTest.expected, conf.Analyze(), currentTest.name)
+
+		err = conf.ValidateStrict()
+		if currentTest.expectedError == nil {
+			assert.Nil(t, err, currentTest.name)
+		} else {
+			assert.ErrorIs(t, err, currentTest.expectedError, currentTest.name)
+		}
+	}
+}
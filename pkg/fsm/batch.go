@@ -0,0 +1,144 @@
+package fsm
+
+import (
+	"context"
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+const batchChunkSize = 256
+
+// BatchResult is the outcome of processing one input of a batch.
+// Index is the position of the input in the batch, State and Valid are what Process returned for it.
+type BatchResult struct {
+	Index int
+	State *string
+	Valid bool
+}
+
+type batchJob struct {
+	index  int
+	input  string
+	result chan BatchResult
+}
+
+// ProcessBatch runs Process over every input using a pool of workers, and returns the results in input order.
+// If workers is less than 1, runtime.GOMAXPROCS(0) workers are used.
+// If the context is cancelled before every input is processed, the context's error is returned with no results.
+func (f *FiniteStateMachine) ProcessBatch(ctx context.Context, inputs []string, workers int) ([]BatchResult, error) {
+	workers = batchWorkers(workers)
+	results := make([]BatchResult, len(inputs))
+
+	// Workers claim the inputs a chunk at a time, so short inputs don't spend most of their time on hand-offs
+	var next atomic.Int64
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for ctx.Err() == nil {
+				start := int(next.Add(batchChunkSize)) - batchChunkSize
+				if start >= len(inputs) {
+					return
+				}
+				end := min(start+batchChunkSize, len(inputs))
+				for index := start; index < end; index++ {
+					state, valid := f.Process(inputs[index])
+					results[index] = BatchResult{Index: index, State: state, Valid: valid}
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// ProcessStream runs Process over every input read from the inputs channel using a pool of workers.
+// Results are sent on the returned channel in the same order the inputs were received, and the
+// channel is closed once inputs is closed and drained, or the context is cancelled.
+// At most workers inputs are in flight at a time, so a slow reader of the results applies back pressure.
+// If workers is less than 1, runtime.GOMAXPROCS(0) workers are used.
+func (f *FiniteStateMachine) ProcessStream(ctx context.Context, inputs <-chan string, workers int) <-chan BatchResult {
+	workers = batchWorkers(workers)
+
+	jobs := make(chan batchJob)
+	// pending holds the result channel of every in-flight job, in input order
+	pending := make(chan chan BatchResult, workers)
+	out := make(chan BatchResult)
+
+	for range workers {
+		go func() {
+			for job := range jobs {
+				state, valid := f.Process(job.input)
+				job.result <- BatchResult{Index: job.index, State: state, Valid: valid}
+			}
+		}()
+	}
+
+	// Reader: hands out jobs in order, registering each job's result channel before the job can run
+	go func() {
+		defer close(jobs)
+		defer close(pending)
+
+		index := 0
+		for {
+			var input string
+			var ok bool
+			select {
+			case <-ctx.Done():
+				return
+			case input, ok = <-inputs:
+				if !ok {
+					return
+				}
+			}
+
+			result := make(chan BatchResult, 1)
+			select {
+			case <-ctx.Done():
+				return
+			case pending <- result:
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case jobs <- batchJob{index: index, input: input, result: result}:
+			}
+			index++
+		}
+	}()
+
+	// Writer: waits on the in-flight jobs in order, so the results come out in input order
+	go func() {
+		defer close(out)
+
+		for result := range pending {
+			var current BatchResult
+			select {
+			case <-ctx.Done():
+				return
+			case current = <-result:
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case out <- current:
+			}
+		}
+	}()
+
+	return out
+}
+
+func batchWorkers(workers int) int {
+	if workers < 1 {
+		return runtime.GOMAXPROCS(0)
+	}
+	return workers
+}
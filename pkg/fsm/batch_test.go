@@ -0,0 +1,134 @@
+package fsm
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func mod3Inputs(count int) []string {
+	random := rand.New(rand.NewSource(1))
+	inputs := make([]string, count)
+	for i := range inputs {
+		inputs[i] = strconv.FormatInt(random.Int63n(1<<40), 2)
+	}
+	// a few invalid inputs mixed in
+	inputs[0] = ""
+	if count > 2 {
+		inputs[2] = "10a1"
+	}
+	return inputs
+}
+
+func TestProcessBatch(t *testing.T) {
+	fsm := newMod3Machine(t)
+	inputs := mod3Inputs(1000)
+
+	for _, workers := range []int{0, 1, 4, 16} {
+		results, err := fsm.ProcessBatch(context.Background(), inputs, workers)
+		assert.Nil(t, err, "workers %d", workers)
+		assert.Equal(t, len(inputs), len(results), "workers %d", workers)
+
+		for i, result := range results {
+			expectedState, expectedValidity := fsm.Process(inputs[i])
+			assert.Equal(t, i, result.Index)
+			assert.Equal(t, expectedValidity, result.Valid, inputs[i])
+			assert.Equal(t, expectedState, result.State, inputs[i])
+		}
+	}
+}
+
+func TestProcessBatchCancelled(t *testing.T) {
+	fsm := newMod3Machine(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	results, err := fsm.ProcessBatch(ctx, mod3Inputs(1000), 4)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Nil(t, results)
+}
+
+func TestProcessStream(t *testing.T) {
+	fsm := newMod3Machine(t)
+	inputs := mod3Inputs(1000)
+
+	in := make(chan string)
+	go func() {
+		defer close(in)
+		for _, input := range inputs {
+			in <- input
+		}
+	}()
+
+	count := 0
+	for result := range fsm.ProcessStream(context.Background(), in, 8) {
+		expectedState, expectedValidity := fsm.Process(inputs[count])
+		assert.Equal(t, count, result.Index)
+		assert.Equal(t, expectedValidity, result.Valid, inputs[count])
+		assert.Equal(t, expectedState, result.State, inputs[count])
+		count++
+	}
+	assert.Equal(t, len(inputs), count)
+}
+
+func TestProcessStreamCancelled(t *testing.T) {
+	fsm := newMod3Machine(t)
+
+	// the input channel is never closed: cancelling the context must still close the results
+	in := make(chan string)
+	ctx, cancel := context.WithCancel(context.Background())
+	results := fsm.ProcessStream(ctx, in, 2)
+
+	in <- "11"
+	first := <-results
+	assert.True(t, first.Valid)
+
+	cancel()
+	for range results {
+	}
+}
+
+func BenchmarkProcessLoop(b *testing.B) {
+	fsm := newMod3Machine(b)
+	inputs := mod3Inputs(10000)
+
+	b.ResetTimer()
+	for range b.N {
+		for _, input := range inputs {
+			fsm.Process(input)
+		}
+	}
+}
+
+func BenchmarkProcessBatch(b *testing.B) {
+	fsm := newMod3Machine(b)
+	inputs := mod3Inputs(10000)
+
+	for _, workers := range []int{1, 4, 0} {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			for range b.N {
+				_, _ = fsm.ProcessBatch(context.Background(), inputs, workers)
+			}
+		})
+	}
+}
+
+func BenchmarkProcessStream(b *testing.B) {
+	fsm := newMod3Machine(b)
+	inputs := mod3Inputs(10000)
+
+	for range b.N {
+		in := make(chan string, len(inputs))
+		for _, input := range inputs {
+			in <- input
+		}
+		close(in)
+		for range fsm.ProcessStream(context.Background(), in, 0) {
+		}
+	}
+}
@@ -0,0 +1,165 @@
+package fsm
+
+import "fmt"
+
+// Builder puts a Config together a piece at a time, as an alternative to the parallel slices NewConfig takes.
+// States are declared as they are first used, and the alphabet is every character used in a transition,
+// plus any added with Alphabet or AlphabetRange. Problems are collected as the builder goes, and all returned together by Build.
+//
+//	conf, err := fsm.NewBuilder().
+//		Initial("S0").
+//		Final("S0", "S1", "S2").
+//		On("S0", "0", "S0").On("S0", "1", "S1").
+//		On("S1", "0", "S2").On("S1", "1", "S0").
+//		On("S2", "0", "S1").On("S2", "1", "S2").
+//		Build()
+type Builder struct {
+	states       []string
+	stateSet     map[string]struct{}
+	alphabet     []rune
+	alphabetSet  map[rune]struct{}
+	initialState string
+	finalStates  []string
+	transitions  []Transition
+	set          map[string]map[rune]string
+	otherwise    map[string]string
+	problems     []error
+
+	alphabetRanges   []RuneRange
+	rangeTransitions []RangeTransition
+}
+
+func NewBuilder() *Builder {
+	return &Builder{
+		stateSet:    make(map[string]struct{}),
+		alphabetSet: make(map[rune]struct{}),
+		set:         make(map[string]map[rune]string),
+		otherwise:   make(map[string]string),
+	}
+}
+
+// State declares states. States also get declared by being used in any other method, so this is only needed
+// to fix the order states are declared in.
+func (b *Builder) State(states ...string) *Builder {
+	for _, state := range states {
+		if _, ok := b.stateSet[state]; !ok {
+			b.stateSet[state] = struct{}{}
+			b.states = append(b.states, state)
+		}
+	}
+	return b
+}
+
+// Alphabet adds characters to the alphabet, for characters that only Otherwise transitions use
+func (b *Builder) Alphabet(inputs ...rune) *Builder {
+	for _, input := range inputs {
+		if _, ok := b.alphabetSet[input]; !ok {
+			b.alphabetSet[input] = struct{}{}
+			b.alphabet = append(b.alphabet, input)
+		}
+	}
+	return b
+}
+
+// AlphabetRange adds whole ranges of runes to the alphabet
+func (b *Builder) AlphabetRange(ranges ...RuneRange) *Builder {
+	b.alphabetRanges = append(b.alphabetRanges, ranges...)
+	return b
+}
+
+// Initial sets the initial state
+func (b *Builder) Initial(state string) *Builder {
+	if b.initialState != "" && b.initialState != state {
+		b.problems = append(b.problems, newProblem(ErrInvalidInitialState, fmt.Sprintf("initial state set twice: %s and %s", b.initialState, state)))
+		return b
+	}
+	b.initialState = state
+	return b.State(state)
+}
+
+// Final marks states as final
+func (b *Builder) Final(states ...string) *Builder {
+	b.finalStates = append(b.finalStates, states...)
+	return b.State(states...)
+}
+
+// On adds a transition from state to next for every character of inputs
+func (b *Builder) On(state string, inputs string, next string) *Builder {
+	b.State(state, next)
+	if inputs == "" {
+		b.problems = append(b.problems, newProblem(ErrInvalidInput, fmt.Sprintf("transition %s -> %s has no inputs", state, next)))
+		return b
+	}
+
+	for _, input := range inputs {
+		b.Alphabet(input)
+		if existing, ok := b.set[state][input]; ok {
+			if existing != next {
+				b.problems = append(b.problems, newProblem(ErrDuplicateTransition, fmt.Sprintf("transition for %s:%c goes to both %s and %s", state, input, existing, next)))
+			}
+			continue
+		}
+		if b.set[state] == nil {
+			b.set[state] = make(map[rune]string)
+		}
+		b.set[state][input] = next
+		b.transitions = append(b.transitions, Transition{State: state, Input: input, ResultState: next})
+	}
+	return b
+}
+
+// OnRange adds a transition from state to next for every rune in the ranges, adding them to the alphabet.
+// Use RangesOf for unicode classes: OnRange("start", fsm.RangesOf(unicode.Letter), "word").
+func (b *Builder) OnRange(state string, inputs []RuneRange, next string) *Builder {
+	b.State(state, next)
+	b.AlphabetRange(inputs...)
+	b.rangeTransitions = append(b.rangeTransitions, RangeTransition{State: state, Inputs: inputs, ResultState: next})
+	return b
+}
+
+// Otherwise sends every character of the alphabet that state has no transition for with On to next
+func (b *Builder) Otherwise(state string, next string) *Builder {
+	b.State(state, next)
+	if existing, ok := b.otherwise[state]; ok && existing != next {
+		b.problems = append(b.problems, newProblem(ErrDuplicateTransition, fmt.Sprintf("otherwise transition for %s goes to both %s and %s", state, existing, next)))
+		return b
+	}
+	b.otherwise[state] = next
+	return b
+}
+
+// Build validates everything added to the builder and returns the config.
+func (b *Builder) Build() (*Config, error) {
+	var alphabet []RuneRange
+	for _, input := range b.alphabet {
+		alphabet = append(alphabet, Single(input))
+	}
+	alphabet = normalizeRanges(append(alphabet, b.alphabetRanges...))
+
+	rangeTransitions := append([]RangeTransition(nil), b.rangeTransitions...)
+	for _, state := range b.states {
+		next, ok := b.otherwise[state]
+		if !ok {
+			continue
+		}
+
+		var covered []RuneRange
+		for input := range b.set[state] {
+			covered = append(covered, Single(input))
+		}
+		for _, transition := range b.rangeTransitions {
+			if transition.State == state {
+				covered = append(covered, transition.Inputs...)
+			}
+		}
+		if remaining := subtractRanges(alphabet, normalizeRanges(covered)); len(remaining) > 0 {
+			rangeTransitions = append(rangeTransitions, RangeTransition{State: state, Inputs: remaining, ResultState: next})
+		}
+	}
+
+	conf, err := newConfig(b.states, b.alphabet, b.alphabetRanges, b.initialState, b.finalStates, b.transitions, rangeTransitions)
+	if problems := validationErrors(append(append([]error(nil), b.problems...), err)...); problems != nil {
+		return nil, problems
+	}
+	return conf, nil
+}
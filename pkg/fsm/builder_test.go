@@ -0,0 +1,120 @@
+package fsm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuilderMod3(t *testing.T) {
+	conf, err := NewBuilder().
+		Initial("S0").
+		Final("S0", "S1", "S2").
+		On("S0", "0", "S0").On("S0", "1", "S1").
+		On("S1", "0", "S2").On("S1", "1", "S0").
+		On("S2", "0", "S1").On("S2", "1", "S2").
+		Build()
+	assert.Nil(t, err)
+	if err != nil {
+		t.Fatal("Building should not have resulted in an error")
+	}
+
+	assert.Equal(t, newMod3Machine(t).Config.Fingerprint(), conf.Fingerprint())
+}
+
+func TestBuilderOtherwise(t *testing.T) {
+	// identifiers: a letter followed by letters or digits
+	conf, err := NewBuilder().
+		Initial("start").
+		Final("ident").
+		On("start", "abcxyz", "ident").
+		Otherwise("start", "error").
+		On("ident", "abcxyz0123456789", "ident").
+		Otherwise("ident", "error").
+		Alphabet('_').
+		Otherwise("error", "error").
+		Build()
+	assert.Nil(t, err)
+	if err != nil {
+		t.Fatal("Building should not have resulted in an error")
+	}
+
+	fsm, err := New(*conf)
+	assert.Nil(t, err)
+	for input, expected := range map[string]bool{"x1": true, "abc": true, "1x": false, "a_b": false, "_": false} {
+		_, accepted := fsm.Process(input)
+		assert.Equal(t, expected, accepted, input)
+	}
+}
+
+func TestBuilderCollectsProblems(t *testing.T) {
+	_, err := NewBuilder().
+		Initial("q0").
+		Initial("q1").
+		Final("q1", "").
+		On("q0", "a", "q1").
+		On("q0", "a", "q0").
+		On("q0", "", "q1").
+		Otherwise("q1", "q0").
+		Otherwise("q1", "q1").
+		Build()
+
+	assert.ErrorIs(t, err, ErrInvalidInitialState)
+	assert.ErrorIs(t, err, ErrDuplicateTransition)
+	assert.ErrorIs(t, err, ErrInvalidInput)
+	assert.ErrorIs(t, err, ErrEmptyState)
+
+	var validationError *ValidationError
+	assert.ErrorAs(t, err, &validationError)
+	assert.Equal(t, []string{
+		"initial state set twice: q0 and q1",
+		"transition for q0:a goes to both q1 and q0",
+		"transition q0 -> q1 has no inputs",
+		"otherwise transition for q1 goes to both q0 and q1",
+		"state cannot be empty",
+		"final state cannot be blank string",
+	}, problemMessages(validationError))
+}
+
+func TestBuilderEmpty(t *testing.T) {
+	_, err := NewBuilder().Build()
+	assert.ErrorIs(t, err, ErrEmptyStates)
+	assert.ErrorIs(t, err, ErrEmptyInitialState)
+}
+
+func problemMessages(validationError *ValidationError) []string {
+	if validationError == nil {
+		return nil
+	}
+	messages := make([]string, len(validationError.Problems))
+	for i, problem := range validationError.Problems {
+		messages[i] = problem.Error()
+	}
+	return messages
+}
+
+func TestBuilderRanges(t *testing.T) {
+	conf, err := NewBuilder().
+		Initial("start").
+		Final("number").
+		OnRange("start", []RuneRange{{'1', '9'}}, "number").
+		On("start", "-", "sign").
+		OnRange("sign", []RuneRange{{'1', '9'}}, "number").
+		OnRange("number", []RuneRange{{'0', '9'}}, "number").
+		Otherwise("start", "error").
+		Otherwise("sign", "error").
+		Otherwise("number", "error").
+		Otherwise("error", "error").
+		Build()
+	assert.Nil(t, err)
+	if err != nil {
+		t.Fatal("Building should not have resulted in an error")
+	}
+
+	fsm, err := New(*conf)
+	assert.Nil(t, err)
+	for input, expected := range map[string]bool{"7": true, "-42": true, "100": true, "042": false, "--1": false, "1-": false} {
+		_, accepted := fsm.Process(input)
+		assert.Equal(t, expected, accepted, input)
+	}
+}
@@ -1,7 +1,10 @@
 package fsm
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"sort"
 	"strings"
 )
 
@@ -9,27 +12,45 @@ type Config struct {
 	initialState string
 	finalStates  map[string]struct{}
 	Transitions  TransitionsMap
+	mode         AlphabetMode
 }
 
+// NewConfig builds and validates a config.
 func NewConfig(states []string, alphabet []rune, initialState string, finalStates []string, transitions []Transition) (*Config, error) {
+	return newConfig(states, alphabet, nil, initialState, finalStates, transitions, nil)
+}
+
+// NewRangeConfig builds and validates a config whose alphabet and transitions are given as ranges of runes,
+// for machines over alphabets too large to list one rune at a time, such as all letters (see RangesOf).
+func NewRangeConfig(states []string, alphabet []RuneRange, initialState string, finalStates []string, transitions []RangeTransition) (*Config, error) {
+	return newConfig(states, nil, alphabet, initialState, finalStates, nil, transitions)
+}
+
+func newConfig(states []string, alphabet []rune, alphabetRanges []RuneRange, initialState string, finalStates []string, transitions []Transition, rangeTransitions []RangeTransition) (*Config, error) {
+	var problems []error
+
 	// Sanity checks: avoid empy input
 	if len(states) == 0 {
-		return nil, ErrEmptyStates
+		problems = append(problems, ErrEmptyStates)
 	}
-	if len(alphabet) == 0 {
-		return nil, ErrEmptyAlphabet
+	if len(alphabet) == 0 && len(alphabetRanges) == 0 {
+		problems = append(problems, ErrEmptyAlphabet)
 	}
 	if initialState == "" {
-		return nil, ErrEmptyInitialState
+		problems = append(problems, ErrEmptyInitialState)
 	}
-	if len(transitions) == 0 {
-		return nil, ErrEmptyTransitions
+	if len(transitions) == 0 && len(rangeTransitions) == 0 {
+		problems = append(problems, ErrEmptyTransitions)
 	}
 	if len(finalStates) == 0 {
-		return nil, ErrEmptyFinalStates
+		problems = append(problems, ErrEmptyFinalStates)
+	}
+	// Nothing past this point makes sense without all the pieces, so validation stops here without them
+	if len(problems) > 0 {
+		return nil, validationErrors(problems...)
 	}
 
-	newConfig := Config{
+	config := Config{
 		initialState: initialState,
 	}
 
@@ -37,17 +58,19 @@ func NewConfig(states []string, alphabet []rune, initialState string, finalState
 	newStates := make(map[string]struct{}, len(states))
 	for _, currentState := range states {
 		if strings.TrimSpace(currentState) == "" {
-			return nil, ErrEmptyState
+			problems = append(problems, ErrEmptyState)
+			continue
 		}
 		newStates[currentState] = struct{}{}
 	}
 
-	newConfig.finalStates = make(map[string]struct{}, len(finalStates))
+	config.finalStates = make(map[string]struct{}, len(finalStates))
 	for _, currentState := range finalStates {
 		if strings.TrimSpace(currentState) == "" {
-			return nil, ErrEmptyState
+			problems = append(problems, ErrEmptyFinalState)
+			continue
 		}
-		newConfig.finalStates[currentState] = struct{}{}
+		config.finalStates[currentState] = struct{}{}
 	}
 
 	newAlphabet := make(map[rune]struct{}, len(alphabet))
@@ -55,32 +78,172 @@ func NewConfig(states []string, alphabet []rune, initialState string, finalState
 		newAlphabet[currentCharacter] = struct{}{}
 	}
 
-	newConfig.Transitions = NewTransitionsMap(newStates, newAlphabet)
+	config.Transitions = NewTransitionsMap(newStates, newAlphabet)
+	if err := config.Transitions.AddAlphabetRanges(alphabetRanges...); err != nil {
+		problems = append(problems, err)
+	}
 	for _, transition := range transitions {
-		transitionError := newConfig.Transitions.NewTransition(transition)
+		transitionError := config.Transitions.NewTransition(transition)
 		if transitionError != nil {
-			return nil, fmt.Errorf("invalid transition for %s:%c:%s - %s", transition.State, transition.Input, transition.ResultState, transitionError)
+			problems = append(problems, fmt.Errorf("invalid transition for %s:%c:%s - %w", transition.State, transition.Input, transition.ResultState, transitionError))
+		}
+	}
+	for _, transition := range rangeTransitions {
+		transitionError := config.Transitions.NewRangeTransition(transition)
+		if transitionError != nil {
+			problems = append(problems, fmt.Errorf("invalid transition for %s:%v:%s - %w", transition.State, transition.Inputs, transition.ResultState, transitionError))
 		}
 	}
 
-	err := newConfig.Validate()
-	if err != nil {
+	problems = append(problems, config.Validate())
+	if err := validationErrors(problems...); err != nil {
 		return nil, err
 	}
 
-	return &newConfig, nil
+	return &config, nil
 }
 
+// Validate checks the initial and final states are known, and that the transitions are complete.
 func (c *Config) Validate() error {
+	var problems []error
+
 	if _, ok := c.Transitions.states[c.initialState]; !ok {
-		return fmt.Errorf("initial state invalid")
+		problems = append(problems, newProblem(ErrInvalidInitialState, fmt.Sprintf("initial state invalid: %s", c.initialState)))
 	}
 
-	for finalState := range c.finalStates {
+	for _, finalState := range c.FinalStates() {
 		if _, ok := c.Transitions.states[finalState]; !ok {
-			return fmt.Errorf("%s final state is invalid", finalState)
+			problems = append(problems, newProblem(ErrInvalidState, fmt.Sprintf("%s final state is invalid", finalState)))
 		}
 	}
 
-	return c.Transitions.Validate()
+	problems = append(problems, c.Transitions.Validate())
+	return validationErrors(problems...)
+}
+
+// Fingerprint returns a hash identifying the machine described by the config: its states, alphabet,
+// initial state, final states and transitions. Two configs describing the same machine have the same fingerprint,
+// regardless of the order things were declared in.
+func (c *Config) Fingerprint() string {
+	hash := sha256.New()
+
+	states := c.Transitions.sortedStates()
+	fmt.Fprintf(hash, "states %d\n", len(states))
+	for _, state := range states {
+		fmt.Fprintf(hash, "%q\n", state)
+	}
+
+	// Hash the alphabet as the largest ranges that every state treats the same way, so that listing runes one by one
+	// or giving them as a range makes no difference
+	alphabet := c.Transitions.classes()
+	var merged []RuneRange
+	for _, class := range alphabet {
+		last := len(merged) - 1
+		if last >= 0 && merged[last].Hi+1 == class.Lo && c.sameTransitions(states, merged[last].Lo, class.Lo) {
+			merged[last].Hi = class.Hi
+			continue
+		}
+		merged = append(merged, class)
+	}
+	fmt.Fprintf(hash, "alphabet %d\n", len(merged))
+	for _, class := range merged {
+		fmt.Fprintf(hash, "%q %q\n", class.Lo, class.Hi)
+	}
+
+	// Only byte machines say so, so that fingerprints of rune machines are unchanged
+	if c.mode == ByteMode {
+		fmt.Fprintf(hash, "mode %s\n", c.mode)
+	}
+
+	fmt.Fprintf(hash, "initial %q\n", c.initialState)
+
+	finalStates := c.FinalStates()
+	fmt.Fprintf(hash, "final %d\n", len(finalStates))
+	for _, state := range finalStates {
+		fmt.Fprintf(hash, "%q\n", state)
+	}
+
+	fmt.Fprintf(hash, "transitions\n")
+	for _, state := range states {
+		for _, class := range merged {
+			if resultState, ok := c.Transitions.lookup(state, class.Lo); ok {
+				fmt.Fprintf(hash, "%q %q %q\n", state, class.Lo, resultState)
+			}
+		}
+	}
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// InitialState returns the state every run starts in
+func (c *Config) InitialState() string {
+	return c.initialState
+}
+
+// States returns every state of the config, sorted
+func (c *Config) States() []string {
+	return c.Transitions.sortedStates()
+}
+
+// FinalStates returns the final states of the config, sorted
+func (c *Config) FinalStates() []string {
+	finalStates := make([]string, 0, len(c.finalStates))
+	for state := range c.finalStates {
+		finalStates = append(finalStates, state)
+	}
+	sort.Strings(finalStates)
+	return finalStates
+}
+
+// IsFinal reports whether the state is a final state
+func (c *Config) IsFinal(state string) bool {
+	_, ok := c.finalStates[state]
+	return ok
+}
+
+// Alphabet returns the runes listed individually in the alphabet of the config, sorted.
+// Runes added as ranges are only included by AlphabetRanges.
+func (c *Config) Alphabet() []rune {
+	return c.Transitions.sortedAlphabet()
+}
+
+// AlphabetRanges returns the whole alphabet of the config, individual runes and ranges, as sorted ranges
+func (c *Config) AlphabetRanges() []RuneRange {
+	return normalizeRanges(c.Transitions.classes())
+}
+
+// Classes splits the alphabet into sorted ranges of runes that every state treats the same way,
+// so anything exploring the machine only needs to try one rune from each
+func (c *Config) Classes() []RuneRange {
+	return c.Transitions.classes()
+}
+
+// CommonClasses splits the alphabets of all the configs together into sorted ranges of runes that every state of
+// every config treats the same way, for exploring several machines side by side
+func CommonClasses(configs ...*Config) []RuneRange {
+	var classes []RuneRange
+	for _, config := range configs {
+		classes = append(classes, config.Transitions.classes()...)
+	}
+	return partitionRanges(normalizeRanges(classes), classes)
+}
+
+// Next returns the state the input leads to from the state, and false if there is no such transition
+func (c *Config) Next(state string, input rune) (string, bool) {
+	if !c.Transitions.inAlphabet(input) {
+		return "", false
+	}
+	return c.Transitions.lookup(state, input)
+}
+
+// sameTransitions reports whether every state goes to the same place on both inputs
+func (c *Config) sameTransitions(states []string, first rune, second rune) bool {
+	for _, state := range states {
+		firstNext, firstOk := c.Transitions.lookup(state, first)
+		secondNext, secondOk := c.Transitions.lookup(state, second)
+		if firstOk != secondOk || firstNext != secondNext {
+			return false
+		}
+	}
+	return true
 }
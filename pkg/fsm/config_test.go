@@ -344,3 +344,66 @@ func TestInValidConfig(t *testing.T) {
 		}
 	}
 }
+
+func TestConfigFingerprint(t *testing.T) {
+	transitions := []Transition{
+		{State: "q0", Input: 'a', ResultState: "q1"},
+		{State: "q0", Input: 'b', ResultState: "q0"},
+		{State: "q1", Input: 'a', ResultState: "q0"},
+		{State: "q1", Input: 'b', ResultState: "q1"},
+	}
+	reversed := []Transition{transitions[3], transitions[2], transitions[1], transitions[0]}
+
+	conf, err := NewConfig([]string{"q0", "q1"}, []rune{'a', 'b'}, "q0", []string{"q1"}, transitions)
+	assert.Nil(t, err)
+	sameConf, err := NewConfig([]string{"q1", "q0"}, []rune{'b', 'a'}, "q0", []string{"q1"}, reversed)
+	assert.Nil(t, err)
+	otherFinals, err := NewConfig([]string{"q0", "q1"}, []rune{'a', 'b'}, "q0", []string{"q0"}, transitions)
+	assert.Nil(t, err)
+	otherInitial, err := NewConfig([]string{"q0", "q1"}, []rune{'a', 'b'}, "q1", []string{"q1"}, transitions)
+	assert.Nil(t, err)
+
+	assert.Equal(t, conf.Fingerprint(), sameConf.Fingerprint(), "declaration order should not matter")
+	assert.NotEqual(t, conf.Fingerprint(), otherFinals.Fingerprint(), "final states should matter")
+	assert.NotEqual(t, conf.Fingerprint(), otherInitial.Fingerprint(), "initial state should matter")
+}
+
+func TestConfigAccessors(t *testing.T) {
+	conf, err := NewConfig([]string{"q1", "q0"}, []rune{'b', 'a'}, "q0", []string{"q1"}, []Transition{
+		{State: "q0", Input: 'a', ResultState: "q1"},
+		{State: "q0", Input: 'b', ResultState: "q0"},
+		{State: "q1", Input: 'a', ResultState: "q0"},
+		{State: "q1", Input: 'b', ResultState: "q1"},
+	})
+	assert.Nil(t, err)
+
+	assert.Equal(t, "q0", conf.InitialState())
+	assert.Equal(t, []string{"q0", "q1"}, conf.States())
+	assert.Equal(t, []string{"q1"}, conf.FinalStates())
+	assert.Equal(t, []rune{'a', 'b'}, conf.Alphabet())
+	assert.True(t, conf.IsFinal("q1"))
+	assert.False(t, conf.IsFinal("q0"))
+
+	next, ok := conf.Next("q0", 'a')
+	assert.True(t, ok)
+	assert.Equal(t, "q1", next)
+	_, ok = conf.Next("q0", 'c')
+	assert.False(t, ok)
+}
+
+func TestCommonClasses(t *testing.T) {
+	letters, err := NewRangeConfig([]string{"q0"}, []RuneRange{{'a', 'z'}}, "q0", []string{"q0"}, []RangeTransition{
+		{State: "q0", Inputs: []RuneRange{{'a', 'm'}, {'n', 'z'}}, ResultState: "q0"},
+	})
+	assert.Nil(t, err)
+	vowels, err := NewRangeConfig([]string{"q0", "q1"}, []RuneRange{{'a', 'a'}, {'e', 'e'}, {'x', '|'}}, "q0", []string{"q1"}, []RangeTransition{
+		{State: "q0", Inputs: []RuneRange{{'a', 'a'}, {'e', 'e'}}, ResultState: "q1"},
+		{State: "q0", Inputs: []RuneRange{{'x', '|'}}, ResultState: "q0"},
+		{State: "q1", Inputs: []RuneRange{{'a', 'a'}, {'e', 'e'}, {'x', '|'}}, ResultState: "q1"},
+	})
+	assert.Nil(t, err)
+
+	// the classes cover both alphabets, cut wherever either config could treat runes differently
+	assert.Equal(t, []RuneRange{{'a', 'a'}, {'b', 'd'}, {'e', 'e'}, {'f', 'w'}, {'x', 'z'}, {'{', '|'}}, CommonClasses(letters, vowels))
+	assert.Equal(t, letters.Classes(), CommonClasses(letters))
+}
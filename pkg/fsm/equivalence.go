@@ -0,0 +1,73 @@
+package fsm
+
+// DistinguishingInput returns the shortest input that one of the configs accepts and the other rejects,
+// and false if they accept exactly the same inputs.
+// The configs may have different alphabets: a character outside a config's alphabet is rejected by it.
+func (c *Config) DistinguishingInput(other *Config) (string, bool) {
+	// Split both alphabets into ranges that both configs treat the same way, and try one rune of each
+	var alphabet []rune
+	for _, class := range CommonClasses(c, other) {
+		alphabet = append(alphabet, class.Lo)
+	}
+
+	// Walk both machines side by side. A state of "" stands for having already rejected the input,
+	// which can't clash with a real state as NewConfig doesn't allow blank states.
+	type pair struct {
+		first  string
+		second string
+	}
+	next := func(conf *Config, state string, input rune) string {
+		if state == "" {
+			return ""
+		}
+		if !conf.Transitions.inAlphabet(input) {
+			return ""
+		}
+		next, _ := conf.Transitions.lookup(state, input)
+		return next
+	}
+	accepts := func(conf *Config, state string) bool {
+		_, ok := conf.finalStates[state]
+		return state != "" && ok
+	}
+
+	seen := make(map[pair]string)
+	var queue []pair
+	visit := func(current pair, input string) bool {
+		if _, ok := seen[current]; ok {
+			return false
+		}
+		seen[current] = input
+		queue = append(queue, current)
+		return accepts(c, current.first) != accepts(other, current.second)
+	}
+	expand := func(current pair, input string) (string, bool) {
+		for _, currentRune := range alphabet {
+			following := pair{next(c, current.first, currentRune), next(other, current.second, currentRune)}
+			if visit(following, input+c.symbolString(currentRune)) {
+				return input + c.symbolString(currentRune), true
+			}
+		}
+		return "", false
+	}
+
+	// The empty input is rejected by both, so comparison starts after the first character
+	if input, ok := expand(pair{c.initialState, other.initialState}, ""); ok {
+		return input, true
+	}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		if input, ok := expand(current, seen[current]); ok {
+			return input, true
+		}
+	}
+
+	return "", false
+}
+
+// Equivalent reports whether the configs accept exactly the same inputs
+func (c *Config) Equivalent(other *Config) bool {
+	_, differ := c.DistinguishingInput(other)
+	return !differ
+}
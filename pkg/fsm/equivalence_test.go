@@ -0,0 +1,42 @@
+package fsm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDistinguishingInput(t *testing.T) {
+	divisible := newMod3Config(t, "S0")
+
+	// the same language with an extra, redundant state
+	redundant, err := NewConfig([]string{"A", "A2", "B", "C"}, []rune{'0', '1'}, "A", []string{"A", "A2"}, []Transition{
+		{State: "A", Input: '0', ResultState: "A2"},
+		{State: "A", Input: '1', ResultState: "B"},
+		{State: "A2", Input: '0', ResultState: "A"},
+		{State: "A2", Input: '1', ResultState: "B"},
+		{State: "B", Input: '0', ResultState: "C"},
+		{State: "B", Input: '1', ResultState: "A"},
+		{State: "C", Input: '0', ResultState: "B"},
+		{State: "C", Input: '1', ResultState: "C"},
+	})
+	assert.Nil(t, err)
+
+	input, differ := divisible.DistinguishingInput(redundant)
+	assert.False(t, differ, input)
+	assert.True(t, divisible.Equivalent(redundant))
+	assert.True(t, redundant.Equivalent(divisible))
+
+	// divisible by three against everything: "1" is the first difference
+	input, differ = divisible.DistinguishingInput(&newMod3Machine(t).Config)
+	assert.True(t, differ)
+	assert.Equal(t, "1", input)
+
+	// different alphabets: the finite language over {a, b} against divisible by three over {0, 1}
+	input, differ = divisible.DistinguishingInput(newFiniteConfig(t))
+	assert.True(t, differ)
+	assert.Equal(t, "0", input)
+	input, differ = newFiniteConfig(t).DistinguishingInput(divisible)
+	assert.True(t, differ)
+	assert.Equal(t, "0", input)
+}
@@ -13,10 +13,47 @@ var (
 	ErrEmptyFinalState     = errors.New("final state cannot be blank string")
 	ErrEmptyResultState    = errors.New("result state cannot be empty")
 	ErrNilTransition       = errors.New("transition cannot be nil")
+	ErrDuplicateTransition = errors.New("transition is defined more than once")
+	ErrOverlappingRange    = errors.New("transitions from the same state overlap")
+	ErrInvalidRange        = errors.New("rune range is empty")
 
 	ErrEmptyAlphabet     = errors.New("must have non-zero amount of inputs")
 	ErrEmptyStates       = errors.New("must have non-zero amount of states")
 	ErrEmptyTransitions  = errors.New("must have transition functions")
 	ErrEmptyFinalStates  = errors.New("must have some final states")
 	ErrEmptyInitialState = errors.New("must have non-blank initial state")
+
+	ErrMissingTransition   = errors.New("no transition for state and input")
+	ErrInvalidUTF8         = errors.New("input is not valid UTF-8")
+	ErrEmptyInput          = errors.New("input cannot be empty")
+	ErrNotAccepted         = errors.New("input does not end in a final state")
+	ErrFingerprintMismatch = errors.New("config fingerprint does not match")
+	ErrInvalidSnapshot     = errors.New("invalid snapshot")
+
+	ErrRunNotFound     = errors.New("run not found")
+	ErrVersionConflict = errors.New("run version conflict")
+	ErrEmptyRunID      = errors.New("run id cannot be empty")
+
+	ErrUnreachableState = errors.New("state is unreachable from initial state")
+	ErrDeadState        = errors.New("state can never reach a final state")
+	ErrEmptyLanguage    = errors.New("machine accepts no input")
+
+	ErrNoMatchingInput = errors.New("no input with the requested outcome")
+	ErrInvalidLength   = errors.New("invalid length distribution")
+
+	ErrEmptyToken   = errors.New("token cannot be empty")
+	ErrUnknownToken = errors.New("token is not in the alphabet")
+	ErrNilConfig    = errors.New("config cannot be nil")
+	ErrNilTokenizer = errors.New("tokenizer cannot be nil")
+
+	ErrEmptyStackAlphabet = errors.New("must have non-zero amount of stack symbols")
+	ErrInvalidStackSymbol = errors.New("invalid stack symbol")
+	ErrNondeterministic   = errors.New("transitions are not deterministic")
+	ErrEpsilonLoop        = errors.New("epsilon transitions loop forever")
+	ErrEmptyStack         = errors.New("stack is empty")
+
+	ErrInvalidTimeout = errors.New("timeout must be a positive duration")
+
+	ErrNilSemiring   = errors.New("semiring cannot be nil")
+	ErrInvalidWeight = errors.New("invalid weight")
 )
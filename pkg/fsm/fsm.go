@@ -23,19 +23,20 @@ func (f *FiniteStateMachine) Process(input string) (*string, bool) {
 	}
 	currentState := f.Config.initialState
 
-	for _, currentRune := range input {
-		_, ok := f.Config.Transitions.alphabet[currentRune]
-		if !ok {
+	for i := 0; i < len(input); {
+		currentRune, size, err := f.Config.decode(input, i)
+		if err != nil {
 			return nil, false
 		}
+		i += size
 
-		// Accepted input, get the current transition for this state
-		inputMap, ok := f.Config.Transitions.transitions[currentState]
+		ok := f.Config.Transitions.inAlphabet(currentRune)
 		if !ok {
 			return nil, false
 		}
-		// is there a valid new state for this input?
-		newState, ok := inputMap[currentRune]
+
+		// Accepted input, is there a valid new state for this input?
+		newState, ok := f.Config.Transitions.lookup(currentState, currentRune)
 		if !ok {
 			return nil, false
 		}
@@ -205,3 +205,36 @@ func TestMod3(t *testing.T) {
 		}
 	}
 }
+
+// mod3Transitions are the transitions of the mod-three machine in TestMod3, shared by the tests that need one
+var mod3Transitions = []Transition{
+	{State: "S0", Input: '0', ResultState: "S0"},
+	{State: "S0", Input: '1', ResultState: "S1"},
+	{State: "S1", Input: '0', ResultState: "S2"},
+	{State: "S1", Input: '1', ResultState: "S0"},
+	{State: "S2", Input: '0', ResultState: "S1"},
+	{State: "S2", Input: '1', ResultState: "S2"},
+}
+
+// newMod3Config builds the mod-three machine with the given final states.
+// With only S0 final, it accepts binary numbers divisible by three.
+func newMod3Config(tb testing.TB, finalStates ...string) *Config {
+	tb.Helper()
+
+	conf, err := NewConfig([]string{"S0", "S1", "S2"}, []rune{'0', '1'}, "S0", finalStates, mod3Transitions)
+	if err != nil {
+		tb.Fatalf("mod3 config should not have resulted in an error: %s", err)
+	}
+	return conf
+}
+
+// newMod3Machine builds the same mod-three machine as TestMod3, with every state final
+func newMod3Machine(tb testing.TB) *FiniteStateMachine {
+	tb.Helper()
+
+	machine, err := New(*newMod3Config(tb, "S0", "S1", "S2"))
+	if err != nil {
+		tb.Fatalf("mod3 machine should not have resulted in an error: %s", err)
+	}
+	return machine
+}
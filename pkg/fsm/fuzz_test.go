@@ -0,0 +1,97 @@
+package fsm
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+)
+
+// splitFuzzList turns a comma separated fuzz argument into a list, so the fuzzer can vary list lengths
+func splitFuzzList(value string) []string {
+	if value == "" {
+		return nil
+	}
+	return strings.Split(value, ",")
+}
+
+func FuzzNewConfig(f *testing.F) {
+	f.Add("q0,q1", "ab", "q0", "q1", "q0 a q1,q0 b q0,q1 a q0,q1 b q1")
+	f.Add("q0", "a", "q0", "q0", "q0 a q0")
+	f.Add("q0,q1", "ab", "q2", "q3", "q0 c q0")
+	f.Add("", "", "", "", "")
+
+	f.Fuzz(func(t *testing.T, states string, alphabet string, initialState string, finalStates string, transitions string) {
+		var parsedTransitions []Transition
+		for _, transition := range splitFuzzList(transitions) {
+			parts := strings.SplitN(transition, " ", 3)
+			if len(parts) != 3 {
+				continue
+			}
+			input := []rune(parts[1])
+			if len(input) != 1 {
+				continue
+			}
+			parsedTransitions = append(parsedTransitions, Transition{State: parts[0], Input: input[0], ResultState: parts[2]})
+		}
+
+		conf, err := NewConfig(splitFuzzList(states), []rune(alphabet), initialState, splitFuzzList(finalStates), parsedTransitions)
+		if err != nil {
+			var validationError *ValidationError
+			if !errors.As(err, &validationError) || len(validationError.Problems) == 0 {
+				t.Fatalf("NewConfig error should be a non-empty ValidationError, got %T: %s", err, err)
+			}
+			if conf != nil {
+				t.Fatal("NewConfig should not return a config along with an error")
+			}
+			return
+		}
+
+		if err := conf.Validate(); err != nil {
+			t.Fatalf("config from NewConfig should validate: %s", err)
+		}
+		if _, err := New(*conf); err != nil {
+			t.Fatalf("config from NewConfig should make a machine: %s", err)
+		}
+		if !conf.Equivalent(conf) {
+			t.Fatal("config should be equivalent to itself")
+		}
+	})
+}
+
+func FuzzProcess(f *testing.F) {
+	f.Add("110")
+	f.Add("0010101010111001")
+	f.Add("")
+	f.Add("10a1")
+	f.Add("\xff01")
+
+	fsm := newMod3Machine(f)
+
+	f.Fuzz(func(t *testing.T, input string) {
+		state, ok := fsm.Process(input)
+
+		// ProcessParallel only splits inputs far longer than the fuzzer makes, so the chunked path is called directly
+		for chunks := 2; chunks <= 4 && input != ""; chunks++ {
+			chunkedState, chunkedOk := fsm.processChunks(splitInput(input, chunks))
+			if chunkedOk != ok || (chunkedState == nil) != (state == nil) || (state != nil && *chunkedState != *state) {
+				t.Fatalf("%q in %d chunks: got %v %v, Process gives %v %v", input, chunks, chunkedState, chunkedOk, state, ok)
+			}
+		}
+
+		// big.Int's parsing also allows signs and underscores, which the machine rightly doesn't
+		isBinary := input != "" && strings.Trim(input, "01") == ""
+		if !isBinary {
+			if ok || state != nil {
+				t.Fatalf("%q is not a binary number and should be rejected", input)
+			}
+			return
+		}
+
+		value, _ := new(big.Int).SetString(input, 2)
+		expected := "S" + new(big.Int).Mod(value, big.NewInt(3)).String()
+		if !ok || state == nil || *state != expected {
+			t.Fatalf("%q should end in %s, got %v %v", input, expected, state, ok)
+		}
+	})
+}
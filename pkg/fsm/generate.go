@@ -0,0 +1,262 @@
+package fsm
+
+import (
+	"fmt"
+	"math/big"
+	"math/rand"
+	"strings"
+)
+
+// How many lengths a Generator draws before giving up on finding one that has a matching input
+const maxGenerateAttempts = 1000
+
+// LengthDistribution picks the length of each generated input
+type LengthDistribution interface {
+	Length(random *rand.Rand) int
+}
+
+// FixedLength always generates inputs of the same length
+type FixedLength int
+
+func (f FixedLength) Length(random *rand.Rand) int {
+	return int(f)
+}
+
+// UniformLength picks a length between Min and Max inclusive, all equally likely
+type UniformLength struct {
+	Min int
+	Max int
+}
+
+func (u UniformLength) Length(random *rand.Rand) int {
+	if u.Max <= u.Min {
+		return u.Min
+	}
+	return u.Min + random.Intn(u.Max-u.Min+1)
+}
+
+// GeometricLength picks short lengths more often than long ones: each extra character is added with probability
+// Continue, starting from Min and stopping at Max (Max of 0 means no limit).
+// Without a Max, Continue must be below 1, or the length would never stop growing.
+type GeometricLength struct {
+	Min      int
+	Max      int
+	Continue float64
+}
+
+// Validate returns ErrInvalidLength if the length would never stop growing
+func (g GeometricLength) Validate() error {
+	if g.Max == 0 && g.Continue >= 1 {
+		return fmt.Errorf("%w: geometric length continues with probability %g and has no Max", ErrInvalidLength, g.Continue)
+	}
+	return nil
+}
+
+// Length returns -1, which no input has, instead of growing forever when Validate fails
+func (g GeometricLength) Length(random *rand.Rand) int {
+	if g.Validate() != nil {
+		return -1
+	}
+	length := g.Min
+	for (g.Max == 0 || length < g.Max) && random.Float64() < g.Continue {
+		length++
+	}
+	return length
+}
+
+// Generator produces random inputs that a machine accepts or rejects, for driving fuzz tests.
+// For a given length, every input of that length with the wanted outcome is equally likely.
+// A Generator is seeded, so the same seed gives the same inputs; it is not safe for concurrent use.
+type Generator struct {
+	config  *Config
+	random  *rand.Rand
+	lengths LengthDistribution
+	// the alphabet, split into classes of runes every state treats the same way
+	classes      []RuneRange
+	alphabetSize int64
+
+	// accepted[n][state] is the number of inputs of length n read from state that end in a final state,
+	// and rejected[n][state] the number that don't (including those that hit a missing transition)
+	accepted []map[string]*big.Int
+	rejected []map[string]*big.Int
+}
+
+// NewGenerator creates a generator drawing input lengths from lengths. The lengths can be nil for a generator that
+// is only used with AcceptedOfLength and RejectedOfLength, and then Accepted and Rejected return ErrInvalidLength.
+func NewGenerator(config *Config, seed int64, lengths LengthDistribution) *Generator {
+	generator := &Generator{
+		config:  config,
+		random:  rand.New(rand.NewSource(seed)),
+		lengths: lengths,
+		classes: config.Transitions.classes(),
+	}
+	for _, class := range generator.classes {
+		generator.alphabetSize += class.Size()
+	}
+	return generator
+}
+
+// Accepted returns a random input the machine accepts, with its length drawn from the generator's length distribution.
+// Lengths that have no accepted inputs are redrawn; ErrNoMatchingInput is returned if none can be found,
+// and ErrInvalidLength if the length distribution can't draw lengths at all.
+func (g *Generator) Accepted() (string, error) {
+	return g.generate(true)
+}
+
+// Rejected returns a random input over the alphabet the machine rejects, with its length drawn from the
+// generator's length distribution.
+func (g *Generator) Rejected() (string, error) {
+	return g.generate(false)
+}
+
+// AcceptedOfLength returns an input of exactly the given length, chosen uniformly among all the inputs of that length the machine accepts
+func (g *Generator) AcceptedOfLength(length int) (string, error) {
+	return g.ofLength(length, true)
+}
+
+// RejectedOfLength returns an input of exactly the given length, chosen uniformly among all the inputs of that length the machine rejects
+func (g *Generator) RejectedOfLength(length int) (string, error) {
+	return g.ofLength(length, false)
+}
+
+// lengthValidator is implemented by length distributions that can be set up so they can't draw a length
+type lengthValidator interface {
+	Validate() error
+}
+
+func (g *Generator) generate(accepted bool) (string, error) {
+	if g.lengths == nil {
+		return "", fmt.Errorf("%w: no length distribution", ErrInvalidLength)
+	}
+	if validator, ok := g.lengths.(lengthValidator); ok {
+		if err := validator.Validate(); err != nil {
+			return "", err
+		}
+	}
+	for range maxGenerateAttempts {
+		input, err := g.ofLength(g.lengths.Length(g.random), accepted)
+		if err == nil {
+			return input, nil
+		}
+	}
+
+	return "", ErrNoMatchingInput
+}
+
+func (g *Generator) ofLength(length int, accepted bool) (string, error) {
+	if length < 0 {
+		return "", fmt.Errorf("%w: negative length %d", ErrNoMatchingInput, length)
+	}
+	// Process never accepts the empty input, whatever the initial state is
+	if length == 0 {
+		if accepted {
+			return "", fmt.Errorf("%w: length 0", ErrNoMatchingInput)
+		}
+		return "", nil
+	}
+
+	g.grow(length)
+	counts := g.rejected
+	if accepted {
+		counts = g.accepted
+	}
+	if counts[length][g.config.initialState].Sign() == 0 {
+		return "", fmt.Errorf("%w: length %d", ErrNoMatchingInput, length)
+	}
+
+	var builder strings.Builder
+	state := g.config.initialState
+	for remaining := length; remaining > 0; remaining-- {
+		// choose each character with probability proportional to the number of matching completions it leaves
+		choice := new(big.Int).Rand(g.random, counts[remaining][state])
+		var chosen RuneRange
+		for _, class := range g.classes {
+			weight := g.completions(state, class, remaining, accepted)
+			if choice.Cmp(weight) < 0 {
+				chosen = class
+				break
+			}
+			choice.Sub(choice, weight)
+		}
+		// every rune of the class leaves the same completions, so any of them will do
+		if chosen.Size() == 1 {
+			g.config.encode(&builder, chosen.Lo)
+		} else {
+			g.config.encode(&builder, chosen.Lo+rune(g.random.Int63n(chosen.Size())))
+		}
+
+		next, ok := g.config.Transitions.lookup(state, chosen.Lo)
+		if !ok {
+			// a missing transition rejects whatever follows, so the rest is free
+			for range remaining - 1 {
+				g.config.encode(&builder, g.randomRune())
+			}
+			break
+		}
+		state = next
+	}
+
+	return builder.String(), nil
+}
+
+// completions returns how many inputs of the given length, starting with a rune of the class from state, are accepted (or rejected)
+func (g *Generator) completions(state string, class RuneRange, length int, accepted bool) *big.Int {
+	size := big.NewInt(class.Size())
+	next, ok := g.config.Transitions.lookup(state, class.Lo)
+	if ok && accepted {
+		return size.Mul(size, g.accepted[length-1][next])
+	}
+	if ok {
+		return size.Mul(size, g.rejected[length-1][next])
+	}
+	// a missing transition: no completion is accepted, and every completion is rejected
+	if accepted {
+		return big.NewInt(0)
+	}
+	return size.Mul(size, new(big.Int).Exp(big.NewInt(g.alphabetSize), big.NewInt(int64(length-1)), nil))
+}
+
+// randomRune picks any rune of the alphabet, all equally likely
+func (g *Generator) randomRune() rune {
+	choice := g.random.Int63n(g.alphabetSize)
+	for _, class := range g.classes {
+		if choice < class.Size() {
+			return class.Lo + rune(choice)
+		}
+		choice -= class.Size()
+	}
+	return g.classes[len(g.classes)-1].Hi
+}
+
+// grow extends the counts up to the given length
+func (g *Generator) grow(length int) {
+	states := g.config.Transitions.sortedStates()
+
+	if len(g.accepted) == 0 {
+		accepted := make(map[string]*big.Int, len(states))
+		rejected := make(map[string]*big.Int, len(states))
+		for _, state := range states {
+			if _, ok := g.config.finalStates[state]; ok {
+				accepted[state], rejected[state] = big.NewInt(1), big.NewInt(0)
+			} else {
+				accepted[state], rejected[state] = big.NewInt(0), big.NewInt(1)
+			}
+		}
+		g.accepted = append(g.accepted, accepted)
+		g.rejected = append(g.rejected, rejected)
+	}
+
+	for current := len(g.accepted); current <= length; current++ {
+		accepted := make(map[string]*big.Int, len(states))
+		rejected := make(map[string]*big.Int, len(states))
+		for _, state := range states {
+			accepted[state], rejected[state] = big.NewInt(0), big.NewInt(0)
+			for _, class := range g.classes {
+				accepted[state].Add(accepted[state], g.completions(state, class, current, true))
+				rejected[state].Add(rejected[state], g.completions(state, class, current, false))
+			}
+		}
+		g.accepted = append(g.accepted, accepted)
+		g.rejected = append(g.rejected, rejected)
+	}
+}
@@ -0,0 +1,134 @@
+package fsm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGeneratorOutcomes(t *testing.T) {
+	conf := newMod3Config(t, "S0")
+	fsm, err := New(*conf)
+	assert.Nil(t, err)
+
+	lengths := []LengthDistribution{
+		FixedLength(12),
+		UniformLength{Min: 1, Max: 40},
+		GeometricLength{Min: 1, Max: 200, Continue: 0.9},
+	}
+
+	for _, distribution := range lengths {
+		generator := NewGenerator(conf, 34, distribution)
+		for range 200 {
+			input, err := generator.Accepted()
+			assert.Nil(t, err)
+			_, ok := fsm.Process(input)
+			assert.True(t, ok, "%q should be accepted", input)
+
+			input, err = generator.Rejected()
+			assert.Nil(t, err)
+			_, ok = fsm.Process(input)
+			assert.False(t, ok, "%q should be rejected", input)
+		}
+	}
+}
+
+func TestGeneratorSeeded(t *testing.T) {
+	conf := newMod3Config(t, "S0")
+	first := NewGenerator(conf, 7, UniformLength{Min: 1, Max: 30})
+	second := NewGenerator(conf, 7, UniformLength{Min: 1, Max: 30})
+
+	for range 50 {
+		a, _ := first.Accepted()
+		b, _ := second.Accepted()
+		assert.Equal(t, a, b)
+	}
+}
+
+func TestGeneratorUniform(t *testing.T) {
+	generator := NewGenerator(newMod3Config(t, "S0"), 1, FixedLength(4))
+
+	// the six 4 digit binary numbers divisible by three should come up about equally often
+	counts := make(map[string]int)
+	for range 6000 {
+		input, err := generator.AcceptedOfLength(4)
+		assert.Nil(t, err)
+		counts[input]++
+	}
+
+	assert.Equal(t, 6, len(counts))
+	for _, input := range []string{"0000", "0011", "0110", "1001", "1100", "1111"} {
+		assert.InDelta(t, 1000, counts[input], 150, input)
+	}
+}
+
+func TestGeneratorNoMatchingInput(t *testing.T) {
+	generator := NewGenerator(newFiniteConfig(t), 1, UniformLength{Min: 4, Max: 10})
+
+	// the finite language has nothing longer than 3
+	_, err := generator.Accepted()
+	assert.ErrorIs(t, err, ErrNoMatchingInput)
+	_, err = generator.AcceptedOfLength(0)
+	assert.ErrorIs(t, err, ErrNoMatchingInput)
+	_, err = generator.AcceptedOfLength(-1)
+	assert.ErrorIs(t, err, ErrNoMatchingInput)
+
+	input, err := generator.AcceptedOfLength(3)
+	assert.Nil(t, err)
+	assert.Equal(t, "aab", input)
+
+	// the mod3 machine accepting everything has no rejected input
+	_, err = NewGenerator(&newMod3Machine(t).Config, 1, FixedLength(5)).Rejected()
+	assert.ErrorIs(t, err, ErrNoMatchingInput)
+}
+
+func TestGeneratorInvalidLength(t *testing.T) {
+	endless := GeometricLength{Min: 1, Continue: 1}
+	generator := NewGenerator(newMod3Config(t, "S0"), 1, endless)
+
+	_, err := generator.Accepted()
+	assert.ErrorIs(t, err, ErrInvalidLength)
+	_, err = generator.Rejected()
+	assert.ErrorIs(t, err, ErrInvalidLength)
+	assert.Equal(t, -1, endless.Length(nil))
+
+	// with a Max, it always draws the Max
+	capped := GeometricLength{Min: 1, Max: 6, Continue: 1}
+	assert.Nil(t, capped.Validate())
+	input, err := NewGenerator(newMod3Config(t, "S0"), 1, capped).Accepted()
+	assert.Nil(t, err)
+	assert.Equal(t, 6, len(input))
+
+	// without a distribution, only the methods given a length work
+	generator = NewGenerator(newMod3Config(t, "S0"), 1, nil)
+	_, err = generator.Accepted()
+	assert.ErrorIs(t, err, ErrInvalidLength)
+	_, err = generator.Rejected()
+	assert.ErrorIs(t, err, ErrInvalidLength)
+	input, err = generator.AcceptedOfLength(3)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(input))
+}
+
+func TestGeneratorMissingTransition(t *testing.T) {
+	transitions := NewTransitionsMap(map[string]struct{}{"q0": {}}, map[rune]struct{}{'a': {}, 'b': {}})
+	assert.Nil(t, transitions.NewTransition(Transition{State: "q0", Input: 'a', ResultState: "q0"}))
+	conf := &Config{
+		initialState: "q0",
+		finalStates:  map[string]struct{}{"q0": {}},
+		Transitions:  transitions,
+	}
+	fsm := &FiniteStateMachine{Config: *conf}
+
+	// only "aaaa" is accepted, and the other 15 inputs of length 4 are rejected
+	generator := NewGenerator(conf, 3, FixedLength(4))
+	seen := make(map[string]struct{})
+	for range 1000 {
+		input, err := generator.Rejected()
+		assert.Nil(t, err)
+		_, ok := fsm.Process(input)
+		assert.False(t, ok, input)
+		seen[input] = struct{}{}
+	}
+	assert.Equal(t, 15, len(seen))
+}
@@ -0,0 +1,173 @@
+package fsm
+
+import (
+	"iter"
+	"math/big"
+)
+
+// CountAccepted returns how many inputs of exactly the given length the machine accepts.
+// Process never accepts the empty input, so the count for length 0 is always 0.
+func (c *Config) CountAccepted(length int) *big.Int {
+	counts := c.CountAcceptedByLength(length)
+	return counts[len(counts)-1]
+}
+
+// CountAcceptedByLength returns how many inputs the machine accepts for every length from 0 up to maxLength.
+// It runs a dynamic program over the transitions: the number of accepted inputs of length n starting from a state
+// is the sum, over the alphabet, of the number of accepted inputs of length n-1 from the state each character leads to.
+func (c *Config) CountAcceptedByLength(maxLength int) []*big.Int {
+	maxLength = max(maxLength, 0)
+	states := c.Transitions.sortedStates()
+	classes := c.Transitions.classes()
+
+	// ways[state] is the number of accepted inputs of the current length, read starting from state
+	ways := make(map[string]*big.Int, len(states))
+	for _, state := range states {
+		ways[state] = big.NewInt(0)
+		if _, ok := c.finalStates[state]; ok {
+			ways[state].SetInt64(1)
+		}
+	}
+
+	counts := make([]*big.Int, maxLength+1)
+	counts[0] = big.NewInt(0)
+	for length := 1; length <= maxLength; length++ {
+		next := make(map[string]*big.Int, len(states))
+		for _, state := range states {
+			total := big.NewInt(0)
+			for _, class := range classes {
+				// every rune of a class goes to the same place
+				if resultState, ok := c.Transitions.lookup(state, class.Lo); ok {
+					total.Add(total, new(big.Int).Mul(ways[resultState], big.NewInt(class.Size())))
+				}
+			}
+			next[state] = total
+		}
+		ways = next
+		counts[length] = new(big.Int).Set(ways[c.initialState])
+	}
+
+	return counts
+}
+
+// IsFinite reports whether the machine accepts only a finite number of inputs.
+// The language is infinite exactly when some cycle passes through states that are both reachable and can still reach a final state.
+func (c *Config) IsFinite() bool {
+	reachable, _ := c.reachableStates()
+	live := c.liveStates()
+	alphabet := c.Transitions.symbols()
+
+	// the states that matter: reachable and live. Look for a cycle among them with a depth first search.
+	const (
+		unvisited = iota
+		visiting
+		done
+	)
+	marks := make(map[string]int)
+	var hasCycle func(state string) bool
+	hasCycle = func(state string) bool {
+		marks[state] = visiting
+		for _, input := range alphabet {
+			next, ok := c.Transitions.lookup(state, input)
+			if !ok {
+				continue
+			}
+			if _, ok := live[next]; !ok {
+				continue
+			}
+			switch marks[next] {
+			case visiting:
+				return true
+			case unvisited:
+				if hasCycle(next) {
+					return true
+				}
+			}
+		}
+		marks[state] = done
+		return false
+	}
+
+	for _, state := range c.Transitions.sortedStates() {
+		_, isReachable := reachable[state]
+		_, isLive := live[state]
+		if isReachable && isLive && marks[state] == unvisited && hasCycle(state) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// AcceptedStrings lazily yields every input the machine accepts, in shortlex order: shorter inputs first,
+// and inputs of the same length in alphabetical order of their runes.
+// For an infinite language the sequence never ends, so the caller must stop ranging over it.
+func (c *Config) AcceptedStrings() iter.Seq[string] {
+	return func(yield func(string) bool) {
+		alphabet := c.Transitions.symbols()
+		classes := c.Transitions.classes()
+
+		// Every accepted input is shorter than the number of states when the language is finite,
+		// so past that length there is nothing left to find
+		maxLength := -1
+		if c.IsFinite() {
+			maxLength = len(c.Transitions.states)
+		}
+
+		// canAccept[n] holds the states from which some input of exactly length n is accepted
+		canAccept := []map[string]struct{}{c.finalStates}
+		extend := func() {
+			previous := canAccept[len(canAccept)-1]
+			current := make(map[string]struct{})
+			for state := range c.Transitions.states {
+				for _, input := range alphabet {
+					next, _ := c.Transitions.lookup(state, input)
+					if _, ok := previous[next]; ok {
+						current[state] = struct{}{}
+						break
+					}
+				}
+			}
+			canAccept = append(canAccept, current)
+		}
+
+		buffer := make([]rune, 0)
+		// walk yields every accepted completion of buffer that is remaining runes long, starting from state
+		var walk func(state string, remaining int) bool
+		walk = func(state string, remaining int) bool {
+			if remaining == 0 {
+				return yield(c.inputOf(buffer))
+			}
+			for _, class := range classes {
+				next, ok := c.Transitions.lookup(state, class.Lo)
+				if !ok {
+					continue
+				}
+				if _, ok := canAccept[remaining-1][next]; !ok {
+					continue
+				}
+				for input := class.Lo; input <= class.Hi; input++ {
+					buffer = append(buffer, input)
+					keepGoing := walk(next, remaining-1)
+					buffer = buffer[:len(buffer)-1]
+					if !keepGoing {
+						return false
+					}
+				}
+			}
+			return true
+		}
+
+		for length := 1; maxLength < 0 || length <= maxLength; length++ {
+			for len(canAccept) <= length {
+				extend()
+			}
+			if _, ok := canAccept[length][c.initialState]; !ok {
+				continue
+			}
+			if !walk(c.initialState, length) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package fsm
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newFiniteConfig accepts exactly "b", "ab" and "aab" over {a, b}
+func newFiniteConfig(tb testing.TB) *Config {
+	tb.Helper()
+
+	conf, err := NewConfig([]string{"q0", "q1", "q2", "done", "sink"}, []rune{'a', 'b'}, "q0", []string{"done"}, []Transition{
+		{State: "q0", Input: 'a', ResultState: "q1"},
+		{State: "q0", Input: 'b', ResultState: "done"},
+		{State: "q1", Input: 'a', ResultState: "q2"},
+		{State: "q1", Input: 'b', ResultState: "done"},
+		{State: "q2", Input: 'a', ResultState: "sink"},
+		{State: "q2", Input: 'b', ResultState: "done"},
+		{State: "done", Input: 'a', ResultState: "sink"},
+		{State: "done", Input: 'b', ResultState: "sink"},
+		{State: "sink", Input: 'a', ResultState: "sink"},
+		{State: "sink", Input: 'b', ResultState: "sink"},
+	})
+	if err != nil {
+		tb.Fatalf("finite config should not have resulted in an error: %s", err)
+	}
+	return conf
+}
+
+// bruteForceAccepted processes every binary string up to maxLength, in shortlex order
+func bruteForceAccepted(fsm *FiniteStateMachine, maxLength int) []string {
+	var accepted []string
+	for length := 1; length <= maxLength; length++ {
+		for value := range 1 << length {
+			input := fmt.Sprintf("%0*b", length, value)
+			if _, ok := fsm.Process(input); ok {
+				accepted = append(accepted, input)
+			}
+		}
+	}
+	return accepted
+}
+
+func TestCountAccepted(t *testing.T) {
+	conf := newMod3Config(t, "S0")
+	fsm, err := New(*conf)
+	assert.Nil(t, err)
+
+	counts := conf.CountAcceptedByLength(10)
+	assert.Equal(t, 11, len(counts))
+	assert.Equal(t, int64(0), counts[0].Int64(), "the empty input is never accepted")
+
+	for length := 1; length <= 10; length++ {
+		expected := 0
+		for _, input := range bruteForceAccepted(fsm, length) {
+			if len(input) == length {
+				expected++
+			}
+		}
+		assert.Equal(t, int64(expected), counts[length].Int64(), "length %d", length)
+		assert.Equal(t, counts[length], conf.CountAccepted(length), "length %d", length)
+	}
+
+	// the mod3 machine accepting everything accepts all 2^n inputs, well past what fits in an int64
+	expected := new(big.Int).Lsh(big.NewInt(1), 200)
+	assert.Equal(t, 0, expected.Cmp(newMod3Machine(t).Config.CountAccepted(200)))
+}
+
+func TestAcceptedStrings(t *testing.T) {
+	conf := newMod3Config(t, "S0")
+	fsm, err := New(*conf)
+	assert.Nil(t, err)
+
+	expected := bruteForceAccepted(fsm, 8)
+	var enumerated []string
+	for input := range conf.AcceptedStrings() {
+		if len(input) > 8 {
+			break
+		}
+		enumerated = append(enumerated, input)
+	}
+	assert.Equal(t, expected, enumerated)
+
+	var finite []string
+	for input := range newFiniteConfig(t).AcceptedStrings() {
+		finite = append(finite, input)
+	}
+	assert.Equal(t, []string{"b", "ab", "aab"}, finite)
+}
+
+func TestIsFinite(t *testing.T) {
+	assert.False(t, newMod3Config(t, "S0").IsFinite())
+	assert.True(t, newFiniteConfig(t).IsFinite())
+
+	// a cycle that can never lead to acceptance doesn't make the language infinite
+	conf, err := NewConfig([]string{"q0", "done", "sink"}, []rune{'a'}, "q0", []string{"done"}, []Transition{
+		{State: "q0", Input: 'a', ResultState: "done"},
+		{State: "done", Input: 'a', ResultState: "sink"},
+		{State: "sink", Input: 'a', ResultState: "sink"},
+	})
+	assert.Nil(t, err)
+	assert.True(t, conf.IsFinite())
+	assert.Equal(t, int64(1), conf.CountAccepted(1).Int64())
+	assert.Equal(t, int64(0), conf.CountAccepted(2).Int64())
+}
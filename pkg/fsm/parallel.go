@@ -0,0 +1,114 @@
+package fsm
+
+import (
+	"runtime"
+	"sort"
+	"sync"
+	"unicode/utf8"
+)
+
+// Inputs shorter than this are not worth splitting up
+const minParallelChunkSize = 4096
+
+// ProcessParallel gives the same result as Process, but splits the input into chunks that are run concurrently.
+//
+// For each chunk, the machine is run from every state at once, giving a mapping of start state to end state for that chunk.
+// The mappings are then composed in order, starting from the initial state, to get the final state.
+// This does (number of states) times the work of Process, so it only pays off for long inputs on machines with few states.
+// If chunks is less than 1, runtime.GOMAXPROCS(0) chunks are used.
+func (f *FiniteStateMachine) ProcessParallel(input string, chunks int) (*string, bool) {
+	if len(input) == 0 {
+		return nil, false
+	}
+	if chunks < 1 {
+		chunks = runtime.GOMAXPROCS(0)
+	}
+	chunks = min(chunks, max(1, len(input)/minParallelChunkSize))
+	if chunks == 1 {
+		return f.Process(input)
+	}
+
+	return f.processChunks(splitInput(input, chunks))
+}
+
+func (f *FiniteStateMachine) processChunks(chunks []string) (*string, bool) {
+	states := f.Config.Transitions.sortedStates()
+
+	mappings := make([][]string, len(chunks))
+	var wg sync.WaitGroup
+	for i, chunk := range chunks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			mappings[i] = f.chunkMapping(chunk, states)
+		}()
+	}
+	wg.Wait()
+
+	currentState := f.Config.initialState
+	for _, mapping := range mappings {
+		if mapping == nil {
+			return nil, false
+		}
+		index := sort.SearchStrings(states, currentState)
+		if index == len(states) || states[index] != currentState || mapping[index] == "" {
+			return nil, false
+		}
+		currentState = mapping[index]
+	}
+
+	if _, ok := f.Config.finalStates[currentState]; !ok {
+		return nil, false
+	}
+
+	return &currentState, true
+}
+
+// chunkMapping runs the chunk from every state, returning the end state for each one, in the order of states.
+// A start state that hits a missing transition maps to "".
+// If the chunk contains a character outside the alphabet (or invalid UTF-8), nil is returned, as the whole input is invalid regardless of state.
+func (f *FiniteStateMachine) chunkMapping(chunk string, states []string) []string {
+	mapping := make([]string, len(states))
+	copy(mapping, states)
+
+	for i := 0; i < len(chunk); {
+		currentRune, size, err := f.Config.decode(chunk, i)
+		if err != nil || !f.Config.Transitions.inAlphabet(currentRune) {
+			return nil
+		}
+		i += size
+
+		for index, currentState := range mapping {
+			if currentState == "" {
+				continue
+			}
+			mapping[index], _ = f.Config.Transitions.lookup(currentState, currentRune)
+		}
+	}
+
+	return mapping
+}
+
+// splitInput cuts the input into (at most) the given number of chunks of similar size, without splitting any runes
+func splitInput(input string, chunks int) []string {
+	result := make([]string, 0, chunks)
+	size := len(input) / chunks
+
+	start := 0
+	for i := 1; i < chunks && start < len(input); i++ {
+		end := max(start, i*size)
+		for end < len(input) && !utf8.RuneStart(input[end]) {
+			end++
+		}
+		if end == start {
+			continue
+		}
+		result = append(result, input[start:end])
+		start = end
+	}
+	if start < len(input) {
+		result = append(result, input[start:])
+	}
+
+	return result
+}
@@ -0,0 +1,84 @@
+package fsm
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func randomBinaryString(random *rand.Rand, length int) string {
+	var builder strings.Builder
+	builder.Grow(length)
+	for range length {
+		builder.WriteByte(byte('0' + random.Intn(2)))
+	}
+	return builder.String()
+}
+
+func TestProcessParallelMatchesProcess(t *testing.T) {
+	fsm := newMod3Machine(t)
+	random := rand.New(rand.NewSource(27))
+
+	for range 20 {
+		input := randomBinaryString(random, 50000+random.Intn(200000))
+		expectedState, expectedValidity := fsm.Process(input)
+
+		for _, chunks := range []int{0, 1, 2, 7, 64} {
+			state, validity := fsm.ProcessParallel(input, chunks)
+			assert.Equal(t, expectedValidity, validity, "chunks %d", chunks)
+			assert.Equal(t, expectedState, state, "chunks %d", chunks)
+		}
+	}
+}
+
+func TestProcessChunksMatchesProcess(t *testing.T) {
+	// short inputs are never split by ProcessParallel, so drive the composition directly
+	fsm := newMod3Machine(t)
+	random := rand.New(rand.NewSource(72))
+
+	for range 500 {
+		input := randomBinaryString(random, 1+random.Intn(100))
+		expectedState, expectedValidity := fsm.Process(input)
+
+		state, validity := fsm.processChunks(splitInput(input, 1+random.Intn(len(input))))
+		assert.Equal(t, expectedValidity, validity, input)
+		assert.Equal(t, expectedState, state, input)
+	}
+}
+
+func TestProcessParallelInvalidInput(t *testing.T) {
+	fsm := newMod3Machine(t)
+	random := rand.New(rand.NewSource(1))
+
+	input := randomBinaryString(random, 100000)
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{name: "empty", input: ""},
+		{name: "invalid character at start", input: "2" + input},
+		{name: "invalid character in middle", input: input[:50000] + "a" + input[50000:]},
+		{name: "invalid character at end", input: input + "\n"},
+	}
+
+	for _, currentTest := range tests {
+		state, validity := fsm.ProcessParallel(currentTest.input, 8)
+		assert.False(t, validity, currentTest.name)
+		assert.Nil(t, state, currentTest.name)
+	}
+}
+
+func TestSplitInput(t *testing.T) {
+	input := "aé€😀bcdefg"
+	for chunks := 1; chunks <= len(input)+2; chunks++ {
+		parts := splitInput(input, chunks)
+		assert.LessOrEqual(t, len(parts), chunks)
+		assert.Equal(t, input, strings.Join(parts, ""))
+		for _, part := range parts {
+			assert.NotEmpty(t, part)
+			assert.True(t, strings.ToValidUTF8(part, "") == part, "chunk %q splits a rune", part)
+		}
+	}
+}
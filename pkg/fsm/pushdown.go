@@ -0,0 +1,387 @@
+package fsm
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// Epsilon is the Input of a PushdownTransition that reads no input, and only looks at the stack
+const Epsilon rune = -1
+
+// PushdownTransition is a Transition that also works the stack: it applies only when Pop is on top of the stack,
+// and replaces it with Push
+type PushdownTransition struct {
+	State string
+	// Input is the rune read, or Epsilon to read nothing
+	Input rune
+	Pop   rune
+	// Push is written top first: after pushing "AB", A is on top. An empty Push just pops.
+	Push        string
+	ResultState string
+}
+
+func (p PushdownTransition) String() string {
+	input := "ε"
+	if p.Input != Epsilon {
+		input = string(p.Input)
+	}
+	return fmt.Sprintf("%s:%s:%c/%s:%s", p.State, input, p.Pop, p.Push, p.ResultState)
+}
+
+type pushdownKey struct {
+	state string
+	input rune
+	top   rune
+}
+
+// PushdownConfig describes a deterministic pushdown automaton: a finite state machine with a stack,
+// which can recognize nested structures such as balanced brackets.
+//
+// Unlike a Config, transitions don't need to be complete: a missing transition rejects the input.
+// Instead, they must be deterministic: for each state and stack top, there can be either one transition
+// per input, or a single Epsilon transition, but not both.
+type PushdownConfig struct {
+	initialState  string
+	initialStack  rune
+	finalStates   map[string]struct{}
+	states        map[string]struct{}
+	alphabet      map[rune]struct{}
+	stackAlphabet map[rune]struct{}
+	transitions   map[pushdownKey]PushdownTransition
+}
+
+// NewPushdownConfig builds and validates a pushdown config. The stack starts out holding just initialStack.
+func NewPushdownConfig(states []string, alphabet []rune, stackAlphabet []rune, initialState string, initialStack rune, finalStates []string, transitions []PushdownTransition) (*PushdownConfig, error) {
+	var problems []error
+
+	if len(states) == 0 {
+		problems = append(problems, ErrEmptyStates)
+	}
+	if len(alphabet) == 0 {
+		problems = append(problems, ErrEmptyAlphabet)
+	}
+	if len(stackAlphabet) == 0 {
+		problems = append(problems, ErrEmptyStackAlphabet)
+	}
+	if initialState == "" {
+		problems = append(problems, ErrEmptyInitialState)
+	}
+	if len(transitions) == 0 {
+		problems = append(problems, ErrEmptyTransitions)
+	}
+	if len(finalStates) == 0 {
+		problems = append(problems, ErrEmptyFinalStates)
+	}
+	if len(problems) > 0 {
+		return nil, validationErrors(problems...)
+	}
+
+	config := PushdownConfig{
+		initialState:  initialState,
+		initialStack:  initialStack,
+		finalStates:   make(map[string]struct{}, len(finalStates)),
+		states:        make(map[string]struct{}, len(states)),
+		alphabet:      make(map[rune]struct{}, len(alphabet)),
+		stackAlphabet: make(map[rune]struct{}, len(stackAlphabet)),
+		transitions:   make(map[pushdownKey]PushdownTransition, len(transitions)),
+	}
+	for _, state := range states {
+		if strings.TrimSpace(state) == "" {
+			problems = append(problems, ErrEmptyState)
+			continue
+		}
+		config.states[state] = struct{}{}
+	}
+	for _, state := range finalStates {
+		if strings.TrimSpace(state) == "" {
+			problems = append(problems, ErrEmptyFinalState)
+			continue
+		}
+		config.finalStates[state] = struct{}{}
+	}
+	for _, input := range alphabet {
+		if input == Epsilon {
+			problems = append(problems, fmt.Errorf("%w: epsilon cannot be in the alphabet", ErrInvalidInput))
+			continue
+		}
+		config.alphabet[input] = struct{}{}
+	}
+	for _, symbol := range stackAlphabet {
+		config.stackAlphabet[symbol] = struct{}{}
+	}
+
+	for _, transition := range transitions {
+		if err := config.addTransition(transition); err != nil {
+			problems = append(problems, fmt.Errorf("invalid transition for %s - %w", transition, err))
+		}
+	}
+
+	problems = append(problems, config.Validate())
+	if err := validationErrors(problems...); err != nil {
+		return nil, err
+	}
+
+	return &config, nil
+}
+
+func (c *PushdownConfig) addTransition(transition PushdownTransition) error {
+	if _, ok := c.states[transition.State]; !ok {
+		return ErrInvalidState
+	}
+	if _, ok := c.alphabet[transition.Input]; !ok && transition.Input != Epsilon {
+		return ErrInvalidInput
+	}
+	if _, ok := c.stackAlphabet[transition.Pop]; !ok {
+		return ErrInvalidStackSymbol
+	}
+	for _, symbol := range transition.Push {
+		if _, ok := c.stackAlphabet[symbol]; !ok {
+			return fmt.Errorf("%w: %c", ErrInvalidStackSymbol, symbol)
+		}
+	}
+	if _, ok := c.states[transition.ResultState]; !ok {
+		return ErrInvalidResultState
+	}
+
+	key := pushdownKey{state: transition.State, input: transition.Input, top: transition.Pop}
+	if _, ok := c.transitions[key]; ok {
+		return ErrDuplicateTransition
+	}
+	c.transitions[key] = transition
+	return nil
+}
+
+// Validate checks the initial and final states and the initial stack symbol are known, that the transitions
+// are deterministic, and that no run of Epsilon transitions can go on forever.
+func (c *PushdownConfig) Validate() error {
+	var problems []error
+
+	if _, ok := c.states[c.initialState]; !ok {
+		problems = append(problems, newProblem(ErrInvalidInitialState, fmt.Sprintf("initial state invalid: %s", c.initialState)))
+	}
+	if _, ok := c.stackAlphabet[c.initialStack]; !ok {
+		problems = append(problems, newProblem(ErrInvalidStackSymbol, fmt.Sprintf("initial stack symbol invalid: %c", c.initialStack)))
+	}
+	for _, finalState := range sortedSet(c.finalStates) {
+		if _, ok := c.states[finalState]; !ok {
+			problems = append(problems, newProblem(ErrInvalidState, fmt.Sprintf("%s final state is invalid", finalState)))
+		}
+	}
+
+	epsilon := make(map[pushdownKey]struct{})
+	for key := range c.transitions {
+		if key.input == Epsilon {
+			epsilon[pushdownKey{state: key.state, top: key.top}] = struct{}{}
+		}
+	}
+	for _, key := range c.sortedKeys() {
+		if key.input == Epsilon {
+			continue
+		}
+		if _, ok := epsilon[pushdownKey{state: key.state, top: key.top}]; ok {
+			problems = append(problems, newProblem(ErrNondeterministic, fmt.Sprintf("state %s has both an epsilon transition and a transition for input %c with %c on top of the stack", key.state, key.input, key.top)))
+		}
+	}
+
+	problems = append(problems, c.epsilonLoops()...)
+	return validationErrors(problems...)
+}
+
+// epsilonLoops finds Epsilon transitions that can loop forever.
+// From each state and stack top with an Epsilon transition, it follows the run of Epsilon transitions as far as it
+// can without knowing what is below the starting top. Any run that loops forever at run time, with whatever stack,
+// has a point after which it never pops below where it was, and from there it does the same as one of these.
+func (c *PushdownConfig) epsilonLoops() []error {
+	var problems []error
+	for _, key := range c.sortedKeys() {
+		if key.input != Epsilon {
+			continue
+		}
+
+		var watch epsilonWatch
+		state, stack := key.state, []rune{key.top}
+		for len(stack) > 0 {
+			top := stack[len(stack)-1]
+			if watch.loops(state, top, len(stack)) {
+				problems = append(problems, newProblem(ErrEpsilonLoop, fmt.Sprintf("epsilon transitions from state %s with %c on top of the stack loop forever", key.state, key.top)))
+				break
+			}
+			transition, ok := c.transitions[pushdownKey{state: state, input: Epsilon, top: top}]
+			if !ok {
+				break
+			}
+			stack = append(stack[:len(stack)-1], pushed(transition.Push)...)
+			state = transition.ResultState
+		}
+	}
+	return problems
+}
+
+// epsilonWatch spots a run of Epsilon transitions that loops forever: one that gets back to the same state and stack
+// top, without having popped below the height it was at the first time. Nothing below that height has changed in
+// between, so the run goes round again and again, whether the stack stays the same height or keeps growing.
+type epsilonWatch struct {
+	seen map[pushdownKey]struct{}
+	// byHeight holds what was seen at each stack height, from 1 up, for forgetting once the stack is popped below it
+	byHeight [][]pushdownKey
+}
+
+// loops records the state and stack top at the height, and reports whether the run is looping forever
+func (e *epsilonWatch) loops(state string, top rune, height int) bool {
+	if e.seen == nil {
+		e.seen = make(map[pushdownKey]struct{})
+	}
+	for len(e.byHeight) > height {
+		for _, key := range e.byHeight[len(e.byHeight)-1] {
+			delete(e.seen, key)
+		}
+		e.byHeight = e.byHeight[:len(e.byHeight)-1]
+	}
+
+	key := pushdownKey{state: state, top: top}
+	if _, ok := e.seen[key]; ok {
+		return true
+	}
+	for len(e.byHeight) < height {
+		e.byHeight = append(e.byHeight, nil)
+	}
+	e.seen[key] = struct{}{}
+	e.byHeight[height-1] = append(e.byHeight[height-1], key)
+	return false
+}
+
+// pushed returns the symbols of a Push in the order they go on the stack, so the first is left on top
+func pushed(push string) []rune {
+	symbols := []rune(push)
+	slices.Reverse(symbols)
+	return symbols
+}
+
+func (c *PushdownConfig) sortedKeys() []pushdownKey {
+	keys := make([]pushdownKey, 0, len(c.transitions))
+	for key := range c.transitions {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].state != keys[j].state {
+			return keys[i].state < keys[j].state
+		}
+		if keys[i].top != keys[j].top {
+			return keys[i].top < keys[j].top
+		}
+		return keys[i].input < keys[j].input
+	})
+	return keys
+}
+
+func sortedSet(set map[string]struct{}) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// PushdownAutomaton runs a PushdownConfig, the way FiniteStateMachine runs a Config
+type PushdownAutomaton struct {
+	Config PushdownConfig
+}
+
+func NewPushdown(config PushdownConfig) (*PushdownAutomaton, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	return &PushdownAutomaton{Config: config}, nil
+}
+
+// Process runs the automaton over the input, like FiniteStateMachine.Process.
+// It returns the final state and true if the input is accepted, and nil and false if it isn't, or is empty.
+func (p *PushdownAutomaton) Process(input string) (*string, bool) {
+	state, err := p.Evaluate(input)
+	if err != nil {
+		return nil, false
+	}
+	return &state, true
+}
+
+// Evaluate runs the automaton over the input, and says why the input is rejected if it is.
+//
+// Epsilon transitions are followed whenever they apply. Once the input has run out, they are only followed until
+// the automaton is in a final state, and the input is accepted if it gets to one.
+func (p *PushdownAutomaton) Evaluate(input string) (string, error) {
+	if len(input) == 0 {
+		return "", ErrEmptyInput
+	}
+
+	c := &p.Config
+	state := c.initialState
+	stack := []rune{c.initialStack}
+
+	// apply takes the transition for the input with the current stack top, if there is one
+	apply := func(input rune) bool {
+		if len(stack) == 0 {
+			return false
+		}
+		transition, ok := c.transitions[pushdownKey{state: state, input: input, top: stack[len(stack)-1]}]
+		if !ok {
+			return false
+		}
+		stack = append(stack[:len(stack)-1], pushed(transition.Push)...)
+		state = transition.ResultState
+		return true
+	}
+
+	// followEpsilon takes Epsilon transitions while they apply, stopping at a final state if atFinal is set.
+	// Validate rules out loops, but this watches for them anyway rather than hang.
+	followEpsilon := func(atFinal bool) error {
+		var watch epsilonWatch
+		for len(stack) > 0 {
+			if _, ok := c.finalStates[state]; ok && atFinal {
+				return nil
+			}
+			if watch.loops(state, stack[len(stack)-1], len(stack)) {
+				return fmt.Errorf("%w: in state %s", ErrEpsilonLoop, state)
+			}
+			if !apply(Epsilon) {
+				return nil
+			}
+		}
+		return nil
+	}
+
+	position := 0
+	for i := 0; i < len(input); {
+		currentRune, size := utf8.DecodeRuneInString(input[i:])
+		if currentRune == utf8.RuneError && size <= 1 {
+			return "", fmt.Errorf("position %d: %w at byte %d", position, ErrInvalidUTF8, i)
+		}
+		i += size
+
+		if _, ok := c.alphabet[currentRune]; !ok {
+			return "", fmt.Errorf("position %d: %w: %q", position, ErrInvalidInput, currentRune)
+		}
+		// epsilon transitions go first, as determinism means there is no transition for the input while one applies
+		if err := followEpsilon(false); err != nil {
+			return "", fmt.Errorf("position %d: %w", position, err)
+		}
+		if len(stack) == 0 {
+			return "", fmt.Errorf("position %d: %w", position, ErrEmptyStack)
+		}
+		if !apply(currentRune) {
+			return "", fmt.Errorf("position %d: %w: %s:%c with %c on top of the stack", position, ErrMissingTransition, state, currentRune, stack[len(stack)-1])
+		}
+		position++
+	}
+
+	if err := followEpsilon(true); err != nil {
+		return "", err
+	}
+	if _, ok := c.finalStates[state]; !ok {
+		return "", fmt.Errorf("%w: ended in state %s", ErrNotAccepted, state)
+	}
+	return state, nil
+}
@@ -0,0 +1,271 @@
+package fsm
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newBalancedPushdown accepts balanced parentheses, keeping a ( on the stack for each one still open
+func newBalancedPushdown(tb testing.TB) *PushdownAutomaton {
+	tb.Helper()
+
+	conf, err := NewPushdownConfig([]string{"balanced", "open"}, []rune{'(', ')'}, []rune{'Z', '('}, "balanced", 'Z', []string{"balanced"}, []PushdownTransition{
+		{State: "balanced", Input: '(', Pop: 'Z', Push: "(Z", ResultState: "open"},
+		{State: "open", Input: '(', Pop: '(', Push: "((", ResultState: "open"},
+		{State: "open", Input: ')', Pop: '(', Push: "", ResultState: "open"},
+		{State: "open", Input: Epsilon, Pop: 'Z', Push: "Z", ResultState: "balanced"},
+	})
+	if err != nil {
+		tb.Fatalf("balanced config should not have resulted in an error: %s", err)
+	}
+	pda, err := NewPushdown(*conf)
+	if err != nil {
+		tb.Fatalf("balanced automaton should not have resulted in an error: %s", err)
+	}
+	return pda
+}
+
+func TestPushdownBalancedParentheses(t *testing.T) {
+	pda := newBalancedPushdown(t)
+
+	type test struct {
+		name     string
+		input    string
+		expected bool
+	}
+
+	tests := []test{
+		{name: "pair", input: "()", expected: true},
+		{name: "nested", input: "(())", expected: true},
+		{name: "sequence", input: "()(())()", expected: true},
+		{name: "unclosed", input: "(()", expected: false},
+		{name: "unopened", input: "())", expected: false},
+		{name: "closed first", input: ")(", expected: false},
+		{name: "outside the alphabet", input: "(x)", expected: false},
+		{name: "empty", input: "", expected: false},
+	}
+
+	for _, currentTest := range tests {
+		t.Run(currentTest.name, func(t *testing.T) {
+			state, accepted := pda.Process(currentTest.input)
+			assert.Equal(t, currentTest.expected, accepted)
+			if currentTest.expected {
+				assert.Equal(t, "balanced", *state)
+			}
+		})
+	}
+
+	// compare against counting, on random inputs
+	random := rand.New(rand.NewSource(1))
+	for range 1000 {
+		var builder strings.Builder
+		for range 1 + random.Intn(12) {
+			builder.WriteByte("()"[random.Intn(2)])
+		}
+		input := builder.String()
+
+		depth, balanced := 0, true
+		for _, r := range input {
+			if r == '(' {
+				depth++
+			} else {
+				depth--
+			}
+			balanced = balanced && depth >= 0
+		}
+		_, accepted := pda.Process(input)
+		assert.Equal(t, balanced && depth == 0, accepted, input)
+	}
+}
+
+func TestPushdownAnBn(t *testing.T) {
+	// keeps an A on the stack for each a not yet matched
+	conf, err := NewPushdownConfig([]string{"start", "as", "bs", "done"}, []rune{'a', 'b'}, []rune{'Z', 'A'}, "start", 'Z', []string{"done"}, []PushdownTransition{
+		{State: "start", Input: 'a', Pop: 'Z', Push: "AZ", ResultState: "as"},
+		{State: "as", Input: 'a', Pop: 'A', Push: "AA", ResultState: "as"},
+		{State: "as", Input: 'b', Pop: 'A', Push: "", ResultState: "bs"},
+		{State: "bs", Input: 'b', Pop: 'A', Push: "", ResultState: "bs"},
+		{State: "bs", Input: Epsilon, Pop: 'Z', Push: "Z", ResultState: "done"},
+	})
+	assert.Nil(t, err)
+	pda, err := NewPushdown(*conf)
+	assert.Nil(t, err)
+	if err != nil {
+		t.Fatal("a^n b^n automaton should not have resulted in an error")
+	}
+
+	for input, expected := range map[string]bool{
+		"ab":       true,
+		"aabb":     true,
+		"aaaabbbb": true,
+		"aab":      false,
+		"abb":      false,
+		"abab":     false,
+		"ba":       false,
+		"a":        false,
+	} {
+		_, accepted := pda.Process(input)
+		assert.Equal(t, expected, accepted, input)
+	}
+}
+
+func TestPushdownEvaluate(t *testing.T) {
+	pda := newBalancedPushdown(t)
+
+	_, err := pda.Evaluate("(()")
+	assert.ErrorIs(t, err, ErrNotAccepted)
+	assert.EqualError(t, err, "input does not end in a final state: ended in state open")
+
+	_, err = pda.Evaluate("())")
+	assert.ErrorIs(t, err, ErrMissingTransition)
+	assert.EqualError(t, err, "position 2: no transition for state and input: balanced:) with Z on top of the stack")
+
+	_, err = pda.Evaluate("(x")
+	assert.ErrorIs(t, err, ErrInvalidInput)
+
+	_, err = pda.Evaluate("(\xff")
+	assert.ErrorIs(t, err, ErrInvalidUTF8)
+
+	_, err = pda.Evaluate("")
+	assert.ErrorIs(t, err, ErrEmptyInput)
+}
+
+func TestPushdownEmptyStack(t *testing.T) {
+	// popping the bottom of the stack without replacing it leaves nothing for the next transition
+	conf, err := NewPushdownConfig([]string{"s"}, []rune{'x'}, []rune{'Z'}, "s", 'Z', []string{"s"}, []PushdownTransition{
+		{State: "s", Input: 'x', Pop: 'Z', Push: "", ResultState: "s"},
+	})
+	assert.Nil(t, err)
+	pda, err := NewPushdown(*conf)
+	assert.Nil(t, err)
+
+	_, accepted := pda.Process("x")
+	assert.True(t, accepted)
+
+	_, err = pda.Evaluate("xx")
+	assert.ErrorIs(t, err, ErrEmptyStack)
+}
+
+func TestNewPushdownConfigValidation(t *testing.T) {
+	type test struct {
+		name             string
+		initialStack     rune
+		transitions      []PushdownTransition
+		expectedProblems []string
+	}
+
+	tests := []test{
+		{
+			name:         "invalid transitions",
+			initialStack: 'Y',
+			transitions: []PushdownTransition{
+				{State: "a", Input: 'x', Pop: 'Z', Push: "Z", ResultState: "b"},
+				{State: "a", Input: 'x', Pop: 'Z', Push: "", ResultState: "a"},
+				{State: "c", Input: 'x', Pop: 'Z', Push: "Z", ResultState: "a"},
+				{State: "a", Input: 'y', Pop: 'Z', Push: "Z", ResultState: "a"},
+				{State: "a", Input: Epsilon, Pop: 'W', Push: "Z", ResultState: "a"},
+				{State: "a", Input: Epsilon, Pop: 'A', Push: "AW", ResultState: "a"},
+			},
+			expectedProblems: []string{
+				"invalid transition for a:x:Z/:a - transition is defined more than once",
+				"invalid transition for c:x:Z/Z:a - invalid state",
+				"invalid transition for a:y:Z/Z:a - invalid input",
+				"invalid transition for a:ε:W/Z:a - invalid stack symbol",
+				"invalid transition for a:ε:A/AW:a - invalid stack symbol: W",
+				"initial stack symbol invalid: Y",
+			},
+		},
+		{
+			name:         "nondeterministic",
+			initialStack: 'Z',
+			transitions: []PushdownTransition{
+				{State: "a", Input: 'x', Pop: 'Z', Push: "Z", ResultState: "b"},
+				{State: "a", Input: Epsilon, Pop: 'Z', Push: "", ResultState: "b"},
+			},
+			expectedProblems: []string{
+				"state a has both an epsilon transition and a transition for input x with Z on top of the stack",
+			},
+		},
+		{
+			name:         "epsilon loop",
+			initialStack: 'Z',
+			transitions: []PushdownTransition{
+				{State: "a", Input: Epsilon, Pop: 'Z', Push: "AZ", ResultState: "b"},
+				{State: "b", Input: Epsilon, Pop: 'A', Push: "ZA", ResultState: "a"},
+			},
+			expectedProblems: []string{
+				"epsilon transitions from state a with Z on top of the stack loop forever",
+				"epsilon transitions from state b with A on top of the stack loop forever",
+			},
+		},
+		{
+			name:         "epsilon loop that pushes two and pops one",
+			initialStack: 'Z',
+			transitions: []PushdownTransition{
+				{State: "a", Input: 'x', Pop: 'Z', Push: "AZ", ResultState: "a"},
+				{State: "a", Input: Epsilon, Pop: 'A', Push: "ZA", ResultState: "b"},
+				{State: "b", Input: Epsilon, Pop: 'Z', Push: "", ResultState: "a"},
+			},
+			expectedProblems: []string{
+				"epsilon transitions from state a with A on top of the stack loop forever",
+			},
+		},
+	}
+
+	for _, currentTest := range tests {
+		t.Run(currentTest.name, func(t *testing.T) {
+			_, err := NewPushdownConfig([]string{"a", "b"}, []rune{'x'}, []rune{'Z', 'A'}, "a", currentTest.initialStack, []string{"b"}, currentTest.transitions)
+			if currentTest.expectedProblems == nil {
+				assert.Nil(t, err)
+				return
+			}
+			var validationError *ValidationError
+			assert.ErrorAs(t, err, &validationError)
+			assert.Equal(t, currentTest.expectedProblems, problemMessages(validationError))
+		})
+	}
+
+	_, err := NewPushdownConfig(nil, nil, nil, "", 'Z', nil, nil)
+	assert.ErrorIs(t, err, ErrEmptyStackAlphabet)
+	assert.ErrorIs(t, err, ErrEmptyStates)
+}
+
+func TestPushdownEpsilonPops(t *testing.T) {
+	// epsilon transitions that pop run out when the stack does, so they can't loop forever
+	conf, err := NewPushdownConfig([]string{"a"}, []rune{'x'}, []rune{'Z', 'A'}, "a", 'Z', []string{"a"}, []PushdownTransition{
+		{State: "a", Input: Epsilon, Pop: 'A', Push: "", ResultState: "a"},
+		{State: "a", Input: 'x', Pop: 'Z', Push: "AAZ", ResultState: "a"},
+	})
+	assert.Nil(t, err)
+	pda, err := NewPushdown(*conf)
+	assert.Nil(t, err)
+
+	state, accepted := pda.Process("xxx")
+	assert.True(t, accepted)
+	assert.Equal(t, "a", *state)
+}
+
+func TestPushdownEpsilonLoopAtRunTime(t *testing.T) {
+	// a config built without validation, so the loop is only caught while running
+	pda := &PushdownAutomaton{Config: PushdownConfig{
+		initialState:  "a",
+		initialStack:  'Z',
+		finalStates:   map[string]struct{}{"c": {}},
+		states:        map[string]struct{}{"a": {}, "b": {}, "c": {}},
+		alphabet:      map[rune]struct{}{'x': {}},
+		stackAlphabet: map[rune]struct{}{'Z': {}, 'A': {}},
+		transitions: map[pushdownKey]PushdownTransition{
+			{state: "a", input: 'x', top: 'Z'}:     {State: "a", Input: 'x', Pop: 'Z', Push: "AZ", ResultState: "a"},
+			{state: "a", input: Epsilon, top: 'A'}: {State: "a", Input: Epsilon, Pop: 'A', Push: "ZA", ResultState: "b"},
+			{state: "b", input: Epsilon, top: 'Z'}: {State: "b", Input: Epsilon, Pop: 'Z', Push: "", ResultState: "a"},
+		},
+	}}
+
+	_, err := pda.Evaluate("x")
+	assert.ErrorIs(t, err, ErrEpsilonLoop)
+	_, err = pda.Evaluate("xx")
+	assert.ErrorIs(t, err, ErrEpsilonLoop)
+}
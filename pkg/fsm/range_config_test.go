@@ -0,0 +1,150 @@
+package fsm
+
+import (
+	"testing"
+	"unicode"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newIdentifierConfig accepts identifiers: a letter, then any letters or digits
+func newIdentifierConfig(tb testing.TB) *Config {
+	tb.Helper()
+
+	letters := RangesOf(unicode.Letter)
+	digits := RangesOf(unicode.Digit)
+	alphabet := append(append([]RuneRange{Single('_')}, letters...), digits...)
+
+	conf, err := NewRangeConfig([]string{"start", "ident", "error"}, alphabet, "start", []string{"ident"}, []RangeTransition{
+		{State: "start", Inputs: letters, ResultState: "ident"},
+		{State: "start", Inputs: append([]RuneRange{Single('_')}, digits...), ResultState: "error"},
+		{State: "ident", Inputs: append(letters, digits...), ResultState: "ident"},
+		{State: "ident", Inputs: []RuneRange{Single('_')}, ResultState: "error"},
+		{State: "error", Inputs: alphabet, ResultState: "error"},
+	})
+	if err != nil {
+		tb.Fatalf("identifier config should not have resulted in an error: %s", err)
+	}
+	return conf
+}
+
+func TestRangeConfigProcess(t *testing.T) {
+	fsm, err := New(*newIdentifierConfig(t))
+	assert.Nil(t, err)
+
+	for input, expected := range map[string]bool{
+		"x":        true,
+		"déjà2vu":  true,
+		"Ωmega9":   true,
+		"変数":       true,
+		"9lives":   false,
+		"snake_it": false,
+		"a b":      false,
+		"":         false,
+	} {
+		_, accepted := fsm.Process(input)
+		assert.Equal(t, expected, accepted, input)
+
+		parallelState, parallelAccepted := fsm.processChunks(splitInput(input, 2))
+		if input != "" {
+			assert.Equal(t, expected, parallelAccepted, input)
+			assert.Equal(t, parallelState != nil, expected, input)
+		}
+	}
+
+	run := fsm.NewRun()
+	assert.Nil(t, run.Feed("ab"))
+	assert.ErrorIs(t, run.Feed(" "), ErrInvalidInput)
+}
+
+func TestRangeConfigValidation(t *testing.T) {
+	// missing transitions for part of a range are reported as the ranges left over
+	_, err := NewRangeConfig([]string{"q0"}, []RuneRange{{'a', 'z'}}, "q0", []string{"q0"}, []RangeTransition{
+		{State: "q0", Inputs: []RuneRange{{'a', 'f'}, {'h', 'z'}}, ResultState: "q0"},
+	})
+	assert.ErrorIs(t, err, ErrMissingTransition)
+	assert.Contains(t, err.Error(), "missing transitions for state q0 for input g")
+
+	_, err = NewRangeConfig([]string{"q0"}, []RuneRange{{'a', 'z'}}, "q0", []string{"q0"}, []RangeTransition{
+		{State: "q0", Inputs: []RuneRange{{'a', 'p'}}, ResultState: "q0"},
+		{State: "q0", Inputs: []RuneRange{{'n', 'z'}}, ResultState: "q0"},
+	})
+	assert.ErrorIs(t, err, ErrOverlappingRange)
+}
+
+func TestRangeConfigAnalysis(t *testing.T) {
+	conf := newIdentifierConfig(t)
+	letters := int64(0)
+	for _, r := range RangesOf(unicode.Letter) {
+		letters += r.Size()
+	}
+	digits := int64(0)
+	for _, r := range RangesOf(unicode.Digit) {
+		digits += r.Size()
+	}
+
+	assert.Equal(t, letters, conf.CountAccepted(1).Int64())
+	assert.Equal(t, letters*(letters+digits), conf.CountAccepted(2).Int64())
+
+	accepted, ok := conf.ShortestAccepted()
+	assert.True(t, ok)
+	assert.Equal(t, "A", accepted)
+	rejected, ok := conf.ShortestRejected()
+	assert.True(t, ok)
+	assert.Equal(t, "0", rejected)
+
+	first := []string{}
+	for input := range conf.AcceptedStrings() {
+		first = append(first, input)
+		if len(first) == 3 {
+			break
+		}
+	}
+	assert.Equal(t, []string{"A", "B", "C"}, first)
+
+	generator := NewGenerator(conf, 39, UniformLength{Min: 1, Max: 10})
+	fsm, err := New(*conf)
+	assert.Nil(t, err)
+	for range 100 {
+		input, err := generator.Accepted()
+		assert.Nil(t, err)
+		_, ok := fsm.Process(input)
+		assert.True(t, ok, input)
+	}
+	assert.Empty(t, conf.Analyze().UnreachableStates)
+}
+
+func TestRangeConfigMatchesSingleRuneConfig(t *testing.T) {
+	ranged, err := NewRangeConfig([]string{"S0", "S1", "S2"}, []RuneRange{{'0', '1'}}, "S0", []string{"S0"}, []RangeTransition{
+		{State: "S0", Inputs: []RuneRange{Single('0')}, ResultState: "S0"},
+		{State: "S0", Inputs: []RuneRange{Single('1')}, ResultState: "S1"},
+		{State: "S1", Inputs: []RuneRange{Single('0')}, ResultState: "S2"},
+		{State: "S1", Inputs: []RuneRange{Single('1')}, ResultState: "S0"},
+		{State: "S2", Inputs: []RuneRange{Single('0')}, ResultState: "S1"},
+		{State: "S2", Inputs: []RuneRange{Single('1')}, ResultState: "S2"},
+	})
+	assert.Nil(t, err)
+
+	single := newMod3Config(t, "S0")
+	assert.True(t, ranged.Equivalent(single))
+	assert.Equal(t, single.Fingerprint(), ranged.Fingerprint())
+	assert.Equal(t, []RuneRange{{'0', '1'}}, ranged.AlphabetRanges())
+
+	// a machine that treats every character the same, written as a range or rune by rune
+	everything, err := NewRangeConfig([]string{"q0"}, []RuneRange{{'a', 'c'}}, "q0", []string{"q0"}, []RangeTransition{
+		{State: "q0", Inputs: []RuneRange{{'a', 'c'}}, ResultState: "q0"},
+	})
+	assert.Nil(t, err)
+	everythingByRune, err := NewConfig([]string{"q0"}, []rune{'a', 'b', 'c'}, "q0", []string{"q0"}, []Transition{
+		{State: "q0", Input: 'a', ResultState: "q0"},
+		{State: "q0", Input: 'b', ResultState: "q0"},
+		{State: "q0", Input: 'c', ResultState: "q0"},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, everythingByRune.Fingerprint(), everything.Fingerprint())
+
+	// equivalence across differently split alphabets
+	input, differ := everything.DistinguishingInput(newFiniteConfig(t))
+	assert.True(t, differ)
+	assert.Equal(t, "a", input)
+}
@@ -0,0 +1,63 @@
+package fsm
+
+import "fmt"
+
+// Run is a stateful run of a FiniteStateMachine: unlike Process, it keeps its current state between calls,
+// so input can be fed to it a piece at a time.
+type Run struct {
+	fsm      *FiniteStateMachine
+	state    string
+	position int
+}
+
+// NewRun starts a new run in the initial state of the machine
+func (f *FiniteStateMachine) NewRun() *Run {
+	return &Run{
+		fsm:   f,
+		state: f.Config.initialState,
+	}
+}
+
+// Feed advances the run over every character of the input.
+// If a character can't be processed, an error is returned and the run is left as it was before the call.
+// Positions in errors count characters from the start of the run, but byte offsets count from the start of input.
+func (r *Run) Feed(input string) error {
+	currentState := r.state
+	position := r.position
+
+	for i := 0; i < len(input); {
+		currentRune, size, err := r.fsm.Config.decode(input, i)
+		if err != nil {
+			return fmt.Errorf("position %d: %w", position, err)
+		}
+		i += size
+
+		newState, err := r.fsm.Config.Transitions.next(currentState, currentRune)
+		if err != nil {
+			return fmt.Errorf("position %d: %w", position, err)
+		}
+
+		currentState = newState
+		position++
+	}
+
+	r.state = currentState
+	r.position = position
+	return nil
+}
+
+// State returns the current state of the run
+func (r *Run) State() string {
+	return r.state
+}
+
+// Position returns the number of characters the run has consumed
+func (r *Run) Position() int {
+	return r.position
+}
+
+// Accepted reports whether the run is currently in a final state
+func (r *Run) Accepted() bool {
+	_, ok := r.fsm.Config.finalStates[r.state]
+	return ok
+}
@@ -0,0 +1,45 @@
+package fsm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunFeed(t *testing.T) {
+	fsm := newMod3Machine(t)
+	run := fsm.NewRun()
+
+	assert.Equal(t, "S0", run.State())
+	assert.Equal(t, 0, run.Position())
+
+	// feeding in pieces gives the same result as processing all at once
+	for _, piece := range []string{"10", "1", "", "0001", "1"} {
+		err := run.Feed(piece)
+		assert.Nil(t, err, piece)
+	}
+	expectedState, _ := fsm.Process("10100011")
+	assert.Equal(t, *expectedState, run.State())
+	assert.Equal(t, 8, run.Position())
+	assert.True(t, run.Accepted())
+}
+
+func TestRunFeedInvalid(t *testing.T) {
+	fsm := newMod3Machine(t)
+	run := fsm.NewRun()
+
+	err := run.Feed("1")
+	assert.Nil(t, err)
+
+	// a bad character part way through leaves the run untouched
+	err = run.Feed("10a1")
+	assert.ErrorIs(t, err, ErrInvalidInput)
+	assert.Contains(t, err.Error(), "position 3")
+	assert.Equal(t, "S1", run.State())
+	assert.Equal(t, 1, run.Position())
+
+	// positions count from the start of the run, byte offsets from the start of this input
+	err = run.Feed("0\xff")
+	assert.ErrorIs(t, err, ErrInvalidUTF8)
+	assert.EqualError(t, err, "position 2: input is not valid UTF-8 at byte 1")
+}
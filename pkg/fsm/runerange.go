@@ -0,0 +1,124 @@
+package fsm
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"unicode"
+)
+
+// RuneRange is every rune from Lo to Hi inclusive
+type RuneRange struct {
+	Lo rune
+	Hi rune
+}
+
+// Single is a RuneRange of just one rune
+func Single(r rune) RuneRange {
+	return RuneRange{Lo: r, Hi: r}
+}
+
+func (r RuneRange) Contains(input rune) bool {
+	return r.Lo <= input && input <= r.Hi
+}
+
+// Size returns the number of runes in the range
+func (r RuneRange) Size() int64 {
+	return int64(r.Hi) - int64(r.Lo) + 1
+}
+
+func (r RuneRange) String() string {
+	if r.Lo == r.Hi {
+		return fmt.Sprintf("%c", r.Lo)
+	}
+	return fmt.Sprintf("%c-%c", r.Lo, r.Hi)
+}
+
+// RangesOf turns a unicode range table, such as unicode.Letter, into rune ranges
+func RangesOf(table *unicode.RangeTable) []RuneRange {
+	var ranges []RuneRange
+	for _, r := range table.R16 {
+		ranges = appendStrided(ranges, rune(r.Lo), rune(r.Hi), rune(r.Stride))
+	}
+	for _, r := range table.R32 {
+		ranges = appendStrided(ranges, rune(r.Lo), rune(r.Hi), rune(r.Stride))
+	}
+	return normalizeRanges(ranges)
+}
+
+func appendStrided(ranges []RuneRange, lo rune, hi rune, stride rune) []RuneRange {
+	if stride <= 1 {
+		return append(ranges, RuneRange{Lo: lo, Hi: hi})
+	}
+	for r := lo; r <= hi; r += stride {
+		ranges = append(ranges, Single(r))
+	}
+	return ranges
+}
+
+// normalizeRanges sorts the ranges and merges any that overlap or touch
+func normalizeRanges(ranges []RuneRange) []RuneRange {
+	sorted := slices.Clone(ranges)
+	slices.SortFunc(sorted, func(a, b RuneRange) int { return int(a.Lo) - int(b.Lo) })
+
+	var normalized []RuneRange
+	for _, r := range sorted {
+		if last := len(normalized) - 1; last >= 0 && r.Lo <= normalized[last].Hi+1 {
+			normalized[last].Hi = max(normalized[last].Hi, r.Hi)
+			continue
+		}
+		normalized = append(normalized, r)
+	}
+	return normalized
+}
+
+// rangesContain reports whether the input is in any of the normalized ranges
+func rangesContain(ranges []RuneRange, input rune) bool {
+	i := sort.Search(len(ranges), func(i int) bool { return ranges[i].Hi >= input })
+	return i < len(ranges) && ranges[i].Lo <= input
+}
+
+// subtractRanges returns the runes of the normalized ranges a that aren't in the normalized ranges b
+func subtractRanges(a []RuneRange, b []RuneRange) []RuneRange {
+	var result []RuneRange
+	j := 0
+	for _, r := range a {
+		lo := r.Lo
+		for j < len(b) && b[j].Hi < lo {
+			j++
+		}
+		for k := j; k < len(b) && b[k].Lo <= r.Hi; k++ {
+			if b[k].Lo > lo {
+				result = append(result, RuneRange{Lo: lo, Hi: b[k].Lo - 1})
+			}
+			lo = max(lo, b[k].Hi+1)
+		}
+		if lo <= r.Hi {
+			result = append(result, RuneRange{Lo: lo, Hi: r.Hi})
+		}
+	}
+	return result
+}
+
+// partitionRanges splits the normalized ranges of domain wherever one of the boundaries starts or ends,
+// so that every resulting range is either entirely inside or entirely outside each boundary
+func partitionRanges(domain []RuneRange, boundaries []RuneRange) []RuneRange {
+	cuts := make([]rune, 0, 2*len(boundaries))
+	for _, r := range boundaries {
+		cuts = append(cuts, r.Lo, r.Hi+1)
+	}
+	slices.Sort(cuts)
+	cuts = slices.Compact(cuts)
+
+	var partition []RuneRange
+	for _, r := range domain {
+		lo := r.Lo
+		i := sort.Search(len(cuts), func(i int) bool { return cuts[i] > lo })
+		for ; i < len(cuts) && cuts[i] <= r.Hi; i++ {
+			partition = append(partition, RuneRange{Lo: lo, Hi: cuts[i] - 1})
+			lo = cuts[i]
+		}
+		partition = append(partition, RuneRange{Lo: lo, Hi: r.Hi})
+	}
+	return partition
+}
@@ -0,0 +1,44 @@
+package fsm
+
+import (
+	"testing"
+	"unicode"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeRanges(t *testing.T) {
+	ranges := normalizeRanges([]RuneRange{{'x', 'z'}, {'a', 'c'}, {'d', 'f'}, {'b', 'b'}, {'0', '9'}, {'y', 'y'}})
+	assert.Equal(t, []RuneRange{{'0', '9'}, {'a', 'f'}, {'x', 'z'}}, ranges)
+
+	assert.True(t, rangesContain(ranges, 'e'))
+	assert.False(t, rangesContain(ranges, 'g'))
+}
+
+func TestSubtractRanges(t *testing.T) {
+	a := []RuneRange{{'a', 'z'}}
+	b := []RuneRange{{'c', 'e'}, {'x', 'x'}, {'z', 'z'}}
+	assert.Equal(t, []RuneRange{{'a', 'b'}, {'f', 'w'}, {'y', 'y'}}, subtractRanges(a, b))
+	assert.Nil(t, subtractRanges(a, []RuneRange{{'0', 'z'}}))
+	assert.Equal(t, a, subtractRanges(a, nil))
+}
+
+func TestPartitionRanges(t *testing.T) {
+	domain := []RuneRange{{'a', 'z'}}
+	boundaries := []RuneRange{{'a', 'f'}, {'d', 'k'}, {'q', 'q'}}
+	assert.Equal(t, []RuneRange{{'a', 'c'}, {'d', 'f'}, {'g', 'k'}, {'l', 'p'}, {'q', 'q'}, {'r', 'z'}}, partitionRanges(domain, boundaries))
+}
+
+func TestRangesOf(t *testing.T) {
+	letters := RangesOf(unicode.Letter)
+	for _, r := range []rune{'a', 'Z', 'é', 'ж', '中'} {
+		assert.True(t, rangesContain(letters, r), "%c should be a letter", r)
+	}
+	for _, r := range []rune{'1', ' ', '_', '€'} {
+		assert.False(t, rangesContain(letters, r), "%c should not be a letter", r)
+	}
+	// the ranges are normalized, so they never touch
+	for i := 1; i < len(letters); i++ {
+		assert.Less(t, letters[i-1].Hi+1, letters[i].Lo)
+	}
+}
@@ -0,0 +1,84 @@
+package fsm
+
+// Searching finds substrings of a larger text that the machine accepts, like regexp's FindIndex functions.
+// Matches are given as byte offsets [start, end) into the text, and are never empty, as Process never accepts
+// the empty input.
+
+// FindIndex returns the leftmost-longest match in the text: of the matches starting earliest, the longest one.
+// It returns nil if there is no match.
+func (f *FiniteStateMachine) FindIndex(text string) []int {
+	matches := f.FindAllIndex(text, 1)
+	if matches == nil {
+		return nil
+	}
+	return matches[0]
+}
+
+// FindAllIndex returns successive non-overlapping leftmost-longest matches in the text, like regexp's FindAllIndex:
+// each search starts where the previous match ended. If n >= 0, at most n matches are returned.
+// It returns nil if there is no match.
+func (f *FiniteStateMachine) FindAllIndex(text string, n int) [][]int {
+	var matches [][]int
+	live := f.Config.liveStates()
+	for start := 0; start < len(text) && (n < 0 || len(matches) < n); {
+		if end, ok := f.longestMatch(text, start, live); ok {
+			matches = append(matches, []int{start, end})
+			start = end
+			continue
+		}
+		_, size, _ := f.Config.decode(text, start)
+		start += size
+	}
+	return matches
+}
+
+// FindAllOverlappingIndex returns every substring of the text the machine accepts, ordered by where they start and
+// then where they end, so matches can overlap and nest: searching for a+ in "aa" finds [0, 1], [0, 2] and [1, 2].
+// It returns nil if there is no match.
+func (f *FiniteStateMachine) FindAllOverlappingIndex(text string) [][]int {
+	var matches [][]int
+	live := f.Config.liveStates()
+	for start := 0; start < len(text); {
+		f.matchEnds(text, start, live, func(end int) {
+			matches = append(matches, []int{start, end})
+		})
+		_, size, _ := f.Config.decode(text, start)
+		start += size
+	}
+	return matches
+}
+
+// longestMatch returns the end of the longest accepted prefix of the text from start
+func (f *FiniteStateMachine) longestMatch(text string, start int, live map[string]struct{}) (int, bool) {
+	end, found := 0, false
+	f.matchEnds(text, start, live, func(matchEnd int) {
+		end, found = matchEnd, true
+	})
+	return end, found
+}
+
+// matchEnds runs the machine from start, calling found with the end of every accepted prefix, shortest first.
+// The run stops early once it reaches a state that can never accept, so a failed search from a position is cheap
+// for machines with a dead sink state.
+func (f *FiniteStateMachine) matchEnds(text string, start int, live map[string]struct{}, found func(end int)) {
+	currentState := f.Config.initialState
+	for i := start; i < len(text); {
+		currentRune, size, err := f.Config.decode(text, i)
+		if err != nil || !f.Config.Transitions.inAlphabet(currentRune) {
+			break
+		}
+		next, ok := f.Config.Transitions.lookup(currentState, currentRune)
+		if !ok {
+			break
+		}
+		if _, ok := live[next]; !ok {
+			break
+		}
+
+		currentState = next
+		i += size
+		if f.Config.IsFinal(currentState) {
+			found(i)
+		}
+	}
+}
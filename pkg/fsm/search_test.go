@@ -0,0 +1,101 @@
+package fsm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFind(t *testing.T) {
+	identifiers, err := New(*newIdentifierConfig(t))
+	assert.Nil(t, err)
+	// accepts exactly "aa"
+	pairConfig, err := NewConfig([]string{"start", "one", "two", "dead"}, []rune{'a', 'b'}, "start", []string{"two"}, []Transition{
+		{State: "start", Input: 'a', ResultState: "one"},
+		{State: "start", Input: 'b', ResultState: "dead"},
+		{State: "one", Input: 'a', ResultState: "two"},
+		{State: "one", Input: 'b', ResultState: "dead"},
+		{State: "two", Input: 'a', ResultState: "dead"},
+		{State: "two", Input: 'b', ResultState: "dead"},
+		{State: "dead", Input: 'a', ResultState: "dead"},
+		{State: "dead", Input: 'b', ResultState: "dead"},
+	})
+	assert.Nil(t, err)
+	pairs, err := New(*pairConfig)
+	assert.Nil(t, err)
+
+	type test struct {
+		name                string
+		fsm                 *FiniteStateMachine
+		text                string
+		expectedAll         [][]int
+		expectedOverlapping [][]int
+	}
+
+	tests := []test{
+		{
+			name:        "identifiers",
+			fsm:         identifiers,
+			text:        "let x1 = déjà+9y",
+			expectedAll: [][]int{{0, 3}, {4, 6}, {9, 15}, {17, 18}},
+			expectedOverlapping: [][]int{
+				{0, 1}, {0, 2}, {0, 3}, {1, 2}, {1, 3}, {2, 3}, {4, 5}, {4, 6},
+				{9, 10}, {9, 12}, {9, 13}, {9, 15}, {10, 12}, {10, 13}, {10, 15}, {12, 13}, {12, 15}, {13, 15}, {17, 18},
+			},
+		},
+		{
+			name:                "overlapping pairs",
+			fsm:                 pairs,
+			text:                "aaab aa",
+			expectedAll:         [][]int{{0, 2}, {5, 7}},
+			expectedOverlapping: [][]int{{0, 2}, {1, 3}, {5, 7}},
+		},
+		{
+			name:                "invalid UTF-8 ends a match",
+			fsm:                 identifiers,
+			text:                "ab\xC3cd",
+			expectedAll:         [][]int{{0, 2}, {3, 5}},
+			expectedOverlapping: [][]int{{0, 1}, {0, 2}, {1, 2}, {3, 4}, {3, 5}, {4, 5}},
+		},
+		{
+			name: "no match",
+			fsm:  pairs,
+			text: "abab",
+		},
+		{
+			name: "empty text",
+			fsm:  pairs,
+			text: "",
+		},
+	}
+
+	for _, currentTest := range tests {
+		t.Run(currentTest.name, func(t *testing.T) {
+			assert.Equal(t, currentTest.expectedAll, currentTest.fsm.FindAllIndex(currentTest.text, -1))
+			assert.Equal(t, currentTest.expectedOverlapping, currentTest.fsm.FindAllOverlappingIndex(currentTest.text))
+
+			if currentTest.expectedAll == nil {
+				assert.Nil(t, currentTest.fsm.FindIndex(currentTest.text))
+			} else {
+				assert.Equal(t, currentTest.expectedAll[0], currentTest.fsm.FindIndex(currentTest.text))
+			}
+
+			// every match is accepted by Process on its own
+			for _, match := range currentTest.fsm.FindAllOverlappingIndex(currentTest.text) {
+				_, accepted := currentTest.fsm.Process(currentTest.text[match[0]:match[1]])
+				assert.True(t, accepted)
+			}
+		})
+	}
+
+	assert.Equal(t, [][]int{{0, 3}, {4, 6}}, identifiers.FindAllIndex("let x1 = y", 2))
+	assert.Nil(t, identifiers.FindAllIndex("let x1 = y", 0))
+}
+
+func TestFindBytes(t *testing.T) {
+	fsm, err := New(*newByteMod3Config(t))
+	assert.Nil(t, err)
+
+	// byte machines match raw bytes, whatever they would be as UTF-8
+	assert.Equal(t, [][]int{{1, 4}}, fsm.FindAllIndex("\xff\x01\x01\x00\xff", -1))
+}
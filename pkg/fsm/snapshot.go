@@ -0,0 +1,122 @@
+package fsm
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+)
+
+const snapshotMagic = "FSMS"
+const snapshotVersion = 1
+
+// Snapshot is the saved position of a Run, so that it can be stopped and resumed later.
+// The fingerprint of the config the run was using is kept, so that a snapshot can't be restored against a different machine.
+type Snapshot struct {
+	State       string `json:"state"`
+	Position    int    `json:"position"`
+	Fingerprint string `json:"fingerprint"`
+}
+
+// Snapshot returns the current position of the run
+func (r *Run) Snapshot() Snapshot {
+	return Snapshot{
+		State:       r.state,
+		Position:    r.position,
+		Fingerprint: r.fsm.Config.Fingerprint(),
+	}
+}
+
+// Restore resumes a run from a snapshot.
+// The snapshot must have been taken from a machine with the same config, otherwise ErrFingerprintMismatch is returned.
+func (f *FiniteStateMachine) Restore(snapshot Snapshot) (*Run, error) {
+	if snapshot.Fingerprint != f.Config.Fingerprint() {
+		return nil, ErrFingerprintMismatch
+	}
+	if _, ok := f.Config.Transitions.states[snapshot.State]; !ok {
+		return nil, fmt.Errorf("%w: %w %s", ErrInvalidSnapshot, ErrInvalidState, snapshot.State)
+	}
+	if snapshot.Position < 0 {
+		return nil, fmt.Errorf("%w: negative position %d", ErrInvalidSnapshot, snapshot.Position)
+	}
+
+	return &Run{
+		fsm:      f,
+		state:    snapshot.State,
+		position: snapshot.Position,
+	}, nil
+}
+
+// MarshalBinary encodes the snapshot in a compact binary form
+func (s Snapshot) MarshalBinary() ([]byte, error) {
+	if s.Position < 0 {
+		return nil, fmt.Errorf("%w: negative position %d", ErrInvalidSnapshot, s.Position)
+	}
+
+	var buffer bytes.Buffer
+	buffer.WriteString(snapshotMagic)
+	buffer.WriteByte(snapshotVersion)
+	writeBinaryString(&buffer, s.Fingerprint)
+	writeBinaryString(&buffer, s.State)
+	buffer.Write(binary.AppendUvarint(nil, uint64(s.Position)))
+
+	return buffer.Bytes(), nil
+}
+
+// UnmarshalBinary decodes a snapshot encoded with MarshalBinary
+func (s *Snapshot) UnmarshalBinary(data []byte) error {
+	reader := bytes.NewReader(data)
+
+	header := make([]byte, len(snapshotMagic)+1)
+	if _, err := io.ReadFull(reader, header); err != nil || string(header[:len(snapshotMagic)]) != snapshotMagic {
+		return fmt.Errorf("%w: bad header", ErrInvalidSnapshot)
+	}
+	if header[len(snapshotMagic)] != snapshotVersion {
+		return fmt.Errorf("%w: unsupported version %d", ErrInvalidSnapshot, header[len(snapshotMagic)])
+	}
+
+	fingerprint, err := readBinaryString(reader)
+	if err != nil {
+		return fmt.Errorf("%w: fingerprint: %w", ErrInvalidSnapshot, err)
+	}
+	state, err := readBinaryString(reader)
+	if err != nil {
+		return fmt.Errorf("%w: state: %w", ErrInvalidSnapshot, err)
+	}
+	position, err := binary.ReadUvarint(reader)
+	if err != nil || position > math.MaxInt {
+		return fmt.Errorf("%w: bad position", ErrInvalidSnapshot)
+	}
+	if reader.Len() != 0 {
+		return fmt.Errorf("%w: %d trailing bytes", ErrInvalidSnapshot, reader.Len())
+	}
+
+	*s = Snapshot{
+		State:       state,
+		Position:    int(position),
+		Fingerprint: fingerprint,
+	}
+	return nil
+}
+
+func writeBinaryString(buffer *bytes.Buffer, value string) {
+	buffer.Write(binary.AppendUvarint(nil, uint64(len(value))))
+	buffer.WriteString(value)
+}
+
+func readBinaryString(reader *bytes.Reader) (string, error) {
+	length, err := binary.ReadUvarint(reader)
+	if err != nil {
+		return "", err
+	}
+	if length > uint64(reader.Len()) {
+		return "", io.ErrUnexpectedEOF
+	}
+
+	value := make([]byte, length)
+	if _, err := io.ReadFull(reader, value); err != nil {
+		return "", err
+	}
+	return string(value), nil
+}
@@ -0,0 +1,99 @@
+package fsm
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSnapshotRestore(t *testing.T) {
+	fsm := newMod3Machine(t)
+	run := fsm.NewRun()
+	assert.Nil(t, run.Feed("1000"))
+
+	snapshot := run.Snapshot()
+	assert.Equal(t, "S2", snapshot.State)
+	assert.Equal(t, 4, snapshot.Position)
+	assert.Equal(t, fsm.Config.Fingerprint(), snapshot.Fingerprint)
+
+	// a separately built machine with the same config can pick the run back up
+	restored, err := newMod3Machine(t).Restore(snapshot)
+	assert.Nil(t, err)
+	if err != nil {
+		t.Fatal("Restoring snapshot should not have resulted in an error")
+	}
+	assert.Nil(t, restored.Feed("1"))
+	assert.Nil(t, run.Feed("1"))
+	assert.Equal(t, run.State(), restored.State())
+	assert.Equal(t, run.Position(), restored.Position())
+}
+
+func TestSnapshotEncoding(t *testing.T) {
+	snapshot := Snapshot{
+		State:       "S1",
+		Position:    123456,
+		Fingerprint: newMod3Machine(t).Config.Fingerprint(),
+	}
+
+	jsonData, err := json.Marshal(snapshot)
+	assert.Nil(t, err)
+	var fromJSON Snapshot
+	assert.Nil(t, json.Unmarshal(jsonData, &fromJSON))
+	assert.Equal(t, snapshot, fromJSON)
+
+	binaryData, err := snapshot.MarshalBinary()
+	assert.Nil(t, err)
+	var fromBinary Snapshot
+	assert.Nil(t, fromBinary.UnmarshalBinary(binaryData))
+	assert.Equal(t, snapshot, fromBinary)
+
+	// every truncation of the encoding is rejected
+	for i := range binaryData {
+		var truncated Snapshot
+		assert.ErrorIs(t, truncated.UnmarshalBinary(binaryData[:i]), ErrInvalidSnapshot, "length %d", i)
+	}
+	var trailing Snapshot
+	assert.ErrorIs(t, trailing.UnmarshalBinary(append(binaryData, 0)), ErrInvalidSnapshot)
+}
+
+func TestRestoreInvalid(t *testing.T) {
+	fsm := newMod3Machine(t)
+
+	other := newMod3Config(t, "S0")
+
+	type test struct {
+		name          string
+		snapshot      Snapshot
+		expectedError error
+	}
+
+	tests := []test{
+		{
+			name:          "different config",
+			snapshot:      Snapshot{State: "S0", Fingerprint: other.Fingerprint()},
+			expectedError: ErrFingerprintMismatch,
+		},
+		{
+			name:          "missing fingerprint",
+			snapshot:      Snapshot{State: "S0"},
+			expectedError: ErrFingerprintMismatch,
+		},
+		{
+			name:          "unknown state",
+			snapshot:      Snapshot{State: "S3", Fingerprint: fsm.Config.Fingerprint()},
+			expectedError: ErrInvalidSnapshot,
+		},
+		{
+			name:          "negative position",
+			snapshot:      Snapshot{State: "S0", Position: -1, Fingerprint: fsm.Config.Fingerprint()},
+			expectedError: ErrInvalidSnapshot,
+		},
+	}
+
+	for _, currentTest := range tests {
+		run, err := fsm.Restore(currentTest.snapshot)
+		assert.ErrorIs(t, err, currentTest.expectedError, currentTest.name)
+		assert.Nil(t, run, currentTest.name)
+	}
+}
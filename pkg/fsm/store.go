@@ -0,0 +1,272 @@
+package fsm
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// StoredRun is what a Store keeps for one machine instance: the snapshot of its run and the version of the record.
+// Versions start at 1 on the first save and go up by one on every save after that.
+type StoredRun struct {
+	ID       string   `json:"id"`
+	Version  int64    `json:"version"`
+	Snapshot Snapshot `json:"snapshot"`
+}
+
+// Store saves and loads the runs of machine instances by ID.
+//
+// Saves use optimistic concurrency: the caller passes the version it last loaded (0 for a new instance),
+// and the save fails with ErrVersionConflict if the stored version has moved on since.
+type Store interface {
+	// Load returns the stored run for the ID, or ErrRunNotFound
+	Load(ctx context.Context, id string) (StoredRun, error)
+	// Save stores the snapshot if the stored version matches expectedVersion, and returns the new version
+	Save(ctx context.Context, id string, expectedVersion int64, snapshot Snapshot) (int64, error)
+	// Delete removes the stored run if the stored version matches expectedVersion
+	Delete(ctx context.Context, id string, expectedVersion int64) error
+}
+
+// SaveRun snapshots the run and saves it to the store under the ID
+func SaveRun(ctx context.Context, store Store, id string, expectedVersion int64, run *Run) (int64, error) {
+	return store.Save(ctx, id, expectedVersion, run.Snapshot())
+}
+
+// LoadRun loads the run stored under the ID and restores it against the machine.
+// The stored version is returned along with the run, to be passed back to the next save.
+func (f *FiniteStateMachine) LoadRun(ctx context.Context, store Store, id string) (*Run, int64, error) {
+	stored, err := store.Load(ctx, id)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	run, err := f.Restore(stored.Snapshot)
+	if err != nil {
+		return nil, 0, fmt.Errorf("run %s: %w", id, err)
+	}
+
+	return run, stored.Version, nil
+}
+
+// MemoryStore is a Store that keeps everything in memory. It is safe for concurrent use.
+type MemoryStore struct {
+	mutex sync.Mutex
+	runs  map[string]StoredRun
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		runs: make(map[string]StoredRun),
+	}
+}
+
+func (m *MemoryStore) Load(ctx context.Context, id string) (StoredRun, error) {
+	if err := ctx.Err(); err != nil {
+		return StoredRun{}, err
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	stored, ok := m.runs[id]
+	if !ok {
+		return StoredRun{}, fmt.Errorf("%w: %s", ErrRunNotFound, id)
+	}
+	return stored, nil
+}
+
+func (m *MemoryStore) Save(ctx context.Context, id string, expectedVersion int64, snapshot Snapshot) (int64, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	if id == "" {
+		return 0, ErrEmptyRunID
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if err := checkVersion(id, m.runs[id].Version, expectedVersion); err != nil {
+		return 0, err
+	}
+
+	stored := StoredRun{
+		ID:       id,
+		Version:  expectedVersion + 1,
+		Snapshot: snapshot,
+	}
+	m.runs[id] = stored
+	return stored.Version, nil
+}
+
+func (m *MemoryStore) Delete(ctx context.Context, id string, expectedVersion int64) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	stored, ok := m.runs[id]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrRunNotFound, id)
+	}
+	if err := checkVersion(id, stored.Version, expectedVersion); err != nil {
+		return err
+	}
+
+	delete(m.runs, id)
+	return nil
+}
+
+// FileStore is a Store that keeps one JSON file per run in a directory.
+// It is safe for concurrent use within a process; the directory should not be shared between processes.
+type FileStore struct {
+	dir   string
+	mutex sync.Mutex
+}
+
+// NewFileStore creates a FileStore in the directory, creating the directory if needed
+func NewFileStore(dir string) (*FileStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	return &FileStore{dir: dir}, nil
+}
+
+func (f *FileStore) Load(ctx context.Context, id string) (StoredRun, error) {
+	if err := ctx.Err(); err != nil {
+		return StoredRun{}, err
+	}
+	path, err := f.path(id)
+	if err != nil {
+		return StoredRun{}, err
+	}
+
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	return f.read(id, path)
+}
+
+func (f *FileStore) Save(ctx context.Context, id string, expectedVersion int64, snapshot Snapshot) (int64, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	path, err := f.path(id)
+	if err != nil {
+		return 0, err
+	}
+
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	var currentVersion int64
+	stored, err := f.read(id, path)
+	switch {
+	case err == nil:
+		currentVersion = stored.Version
+	case !errors.Is(err, ErrRunNotFound):
+		return 0, err
+	}
+	if err := checkVersion(id, currentVersion, expectedVersion); err != nil {
+		return 0, err
+	}
+
+	stored = StoredRun{
+		ID:       id,
+		Version:  expectedVersion + 1,
+		Snapshot: snapshot,
+	}
+	data, err := json.Marshal(stored)
+	if err != nil {
+		return 0, err
+	}
+
+	// Write to a temporary file first, so a crash part way through never leaves a half written run behind
+	temp, err := os.CreateTemp(f.dir, ".run-*")
+	if err != nil {
+		return 0, err
+	}
+	defer os.Remove(temp.Name())
+
+	if _, err := temp.Write(data); err != nil {
+		temp.Close()
+		return 0, err
+	}
+	if err := temp.Close(); err != nil {
+		return 0, err
+	}
+	if err := os.Rename(temp.Name(), path); err != nil {
+		return 0, err
+	}
+
+	return stored.Version, nil
+}
+
+func (f *FileStore) Delete(ctx context.Context, id string, expectedVersion int64) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	path, err := f.path(id)
+	if err != nil {
+		return err
+	}
+
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	stored, err := f.read(id, path)
+	if err != nil {
+		return err
+	}
+	if err := checkVersion(id, stored.Version, expectedVersion); err != nil {
+		return err
+	}
+
+	return os.Remove(path)
+}
+
+func (f *FileStore) read(id string, path string) (StoredRun, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return StoredRun{}, fmt.Errorf("%w: %s", ErrRunNotFound, id)
+	}
+	if err != nil {
+		return StoredRun{}, err
+	}
+
+	var stored StoredRun
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return StoredRun{}, fmt.Errorf("run %s: %w", id, err)
+	}
+	return stored, nil
+}
+
+// IDs longer than this are hashed instead of hex encoded, to keep file names within the usual limit of 255 bytes
+const maxEncodedIDLength = 100
+
+// path maps an ID to its file. IDs are hex encoded so that any ID is a safe file name, and long IDs are named by
+// their SHA-256 instead, with a different extension so they can't be mistaken for a short ID.
+func (f *FileStore) path(id string) (string, error) {
+	if id == "" {
+		return "", ErrEmptyRunID
+	}
+	if len(id) > maxEncodedIDLength {
+		return filepath.Join(f.dir, fmt.Sprintf("%x.sha256.json", sha256.Sum256([]byte(id)))), nil
+	}
+	return filepath.Join(f.dir, fmt.Sprintf("%x.json", id)), nil
+}
+
+func checkVersion(id string, currentVersion int64, expectedVersion int64) error {
+	if currentVersion != expectedVersion {
+		return fmt.Errorf("%w: %s is at version %d, expected %d", ErrVersionConflict, id, currentVersion, expectedVersion)
+	}
+	return nil
+}
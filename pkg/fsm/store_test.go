@@ -0,0 +1,117 @@
+package fsm
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStores(t *testing.T) {
+	fileStore, err := NewFileStore(t.TempDir())
+	assert.Nil(t, err)
+
+	stores := map[string]Store{
+		"memory": NewMemoryStore(),
+		"file":   fileStore,
+	}
+
+	for name, store := range stores {
+		t.Run(name, func(t *testing.T) {
+			testStore(t, store)
+		})
+	}
+}
+
+func testStore(t *testing.T, store Store) {
+	ctx := context.Background()
+	fsm := newMod3Machine(t)
+
+	_, _, err := fsm.LoadRun(ctx, store, "order/1")
+	assert.ErrorIs(t, err, ErrRunNotFound)
+
+	_, err = store.Save(ctx, "", 0, Snapshot{})
+	assert.ErrorIs(t, err, ErrEmptyRunID)
+
+	// first save of a new instance
+	run := fsm.NewRun()
+	assert.Nil(t, run.Feed("10"))
+	version, err := SaveRun(ctx, store, "order/1", 0, run)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), version)
+
+	// saving it as new again conflicts
+	_, err = SaveRun(ctx, store, "order/1", 0, run)
+	assert.ErrorIs(t, err, ErrVersionConflict)
+
+	// load, continue and save
+	loaded, loadedVersion, err := fsm.LoadRun(ctx, store, "order/1")
+	assert.Nil(t, err)
+	assert.Equal(t, version, loadedVersion)
+	assert.Equal(t, "S2", loaded.State())
+	assert.Nil(t, loaded.Feed("1"))
+	version, err = SaveRun(ctx, store, "order/1", loadedVersion, loaded)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(2), version)
+
+	// a writer that still holds the old version loses
+	_, err = SaveRun(ctx, store, "order/1", loadedVersion, run)
+	assert.ErrorIs(t, err, ErrVersionConflict)
+
+	stored, err := store.Load(ctx, "order/1")
+	assert.Nil(t, err)
+	assert.Equal(t, "order/1", stored.ID)
+	assert.Equal(t, int64(2), stored.Version)
+	assert.Equal(t, loaded.Snapshot(), stored.Snapshot)
+
+	// a different machine refuses the stored run
+	otherFSM, err := New(*newMod3Config(t, "S0"))
+	assert.Nil(t, err)
+	_, _, err = otherFSM.LoadRun(ctx, store, "order/1")
+	assert.ErrorIs(t, err, ErrFingerprintMismatch)
+
+	// delete checks the version too
+	assert.ErrorIs(t, store.Delete(ctx, "order/1", 1), ErrVersionConflict)
+	assert.Nil(t, store.Delete(ctx, "order/1", 2))
+	assert.ErrorIs(t, store.Delete(ctx, "order/1", 2), ErrRunNotFound)
+	_, err = store.Load(ctx, "order/1")
+	assert.ErrorIs(t, err, ErrRunNotFound)
+
+	// IDs too long to be file names themselves still work, and stay apart
+	long := strings.Repeat("order/", 200)
+	_, err = store.Save(ctx, long+"1", 0, run.Snapshot())
+	assert.Nil(t, err)
+	_, err = store.Load(ctx, long+"2")
+	assert.ErrorIs(t, err, ErrRunNotFound)
+	stored, err = store.Load(ctx, long+"1")
+	assert.Nil(t, err)
+	assert.Equal(t, long+"1", stored.ID)
+	assert.Nil(t, store.Delete(ctx, long+"1", 1))
+
+	// concurrent writers with the same version: exactly one wins
+	var wg sync.WaitGroup
+	var mutex sync.Mutex
+	wins := 0
+	for i := range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := store.Save(ctx, "race", 0, Snapshot{State: fmt.Sprintf("S%d", i%3)})
+			if err == nil {
+				mutex.Lock()
+				wins++
+				mutex.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, 1, wins)
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err = store.Load(cancelled, "race")
+	assert.ErrorIs(t, err, context.Canceled)
+}
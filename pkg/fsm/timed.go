@@ -0,0 +1,150 @@
+package fsm
+
+import (
+	"fmt"
+	"time"
+)
+
+// Clock tells a TimedRun the time, so tests can swap in one they control
+type Clock interface {
+	Now() time.Time
+}
+
+// SystemClock is the Clock of the real time
+type SystemClock struct{}
+
+func (SystemClock) Now() time.Time {
+	return time.Now()
+}
+
+// Timeout is a transition a TimedRun takes on its own, once it has had no input for After while in State
+type Timeout struct {
+	State       string
+	After       time.Duration
+	ResultState string
+}
+
+func (t Timeout) String() string {
+	return fmt.Sprintf("%s:%s:%s", t.State, t.After, t.ResultState)
+}
+
+// TimedRun is a Run with timeouts, such as going to an expired state if there is no input within 30 seconds.
+//
+// Timeouts fire lazily, whenever the run is used: each method first takes every timeout that is due by the time on
+// the clock, one after another, as if each had fired right when it was due. Any input restarts the wait,
+// even input that leaves the run in the same state.
+type TimedRun struct {
+	run      *Run
+	clock    Clock
+	timeouts map[string]Timeout
+	// since is when the run last had input, or entered its state by a timeout
+	since time.Time
+}
+
+// NewTimedRun starts a new run in the initial state of the machine, with at most one timeout per state.
+// A nil clock uses the SystemClock. Each timeout must be positive, and from and to states of the machine.
+func (f *FiniteStateMachine) NewTimedRun(clock Clock, timeouts []Timeout) (*TimedRun, error) {
+	if clock == nil {
+		clock = SystemClock{}
+	}
+
+	var problems []error
+	states := f.Config.Transitions.states
+	byState := make(map[string]Timeout, len(timeouts))
+	for _, timeout := range timeouts {
+		_, validState := states[timeout.State]
+		_, validResult := states[timeout.ResultState]
+		_, exists := byState[timeout.State]
+
+		var err error
+		switch {
+		case !validState:
+			err = ErrInvalidState
+		case !validResult:
+			err = ErrInvalidResultState
+		case timeout.After <= 0:
+			err = ErrInvalidTimeout
+		case exists:
+			err = ErrDuplicateTransition
+		default:
+			byState[timeout.State] = timeout
+			continue
+		}
+		problems = append(problems, fmt.Errorf("invalid timeout for %s - %w", timeout, err))
+	}
+	if err := validationErrors(problems...); err != nil {
+		return nil, err
+	}
+
+	return &TimedRun{
+		run:      f.NewRun(),
+		clock:    clock,
+		timeouts: byState,
+		since:    clock.Now(),
+	}, nil
+}
+
+// tick takes every timeout that is due.
+// Once the timeouts go round a cycle of states, whole laps of the cycle are skipped, so a long gap with short
+// timeouts doesn't take one step per timeout.
+func (t *TimedRun) tick() {
+	now := t.clock.Now()
+	entered := make(map[string]time.Time)
+	for {
+		timeout, ok := t.timeouts[t.run.state]
+		if !ok || now.Sub(t.since) < timeout.After {
+			return
+		}
+		if previous, ok := entered[t.run.state]; ok {
+			lap := t.since.Sub(previous)
+			t.since = t.since.Add(now.Sub(t.since) / lap * lap)
+			clear(entered)
+			continue
+		}
+		entered[t.run.state] = t.since
+
+		t.since = t.since.Add(timeout.After)
+		t.run.state = timeout.ResultState
+	}
+}
+
+// Feed takes any timeouts that are due, then advances the run over every character of the input, like Run.Feed.
+// If a character can't be processed, an error is returned and the run is left as it was after the timeouts.
+func (t *TimedRun) Feed(input string) error {
+	t.tick()
+	if err := t.run.Feed(input); err != nil {
+		return err
+	}
+	if len(input) > 0 {
+		t.since = t.clock.Now()
+	}
+	return nil
+}
+
+// State returns the current state of the run, after any timeouts that are due
+func (t *TimedRun) State() string {
+	t.tick()
+	return t.run.State()
+}
+
+// Position returns the number of characters the run has consumed. Timeouts don't consume anything.
+func (t *TimedRun) Position() int {
+	return t.run.Position()
+}
+
+// Accepted reports whether the run is currently in a final state, after any timeouts that are due
+func (t *TimedRun) Accepted() bool {
+	t.tick()
+	return t.run.Accepted()
+}
+
+// Deadline returns when the timeout of the current state fires if there is no input before then,
+// and false if the current state has no timeout
+func (t *TimedRun) Deadline() (time.Time, bool) {
+	t.tick()
+	timeout, ok := t.timeouts[t.run.state]
+	if !ok {
+		return time.Time{}, false
+	}
+	return t.since.Add(timeout.After), true
+}
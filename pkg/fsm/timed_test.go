@@ -0,0 +1,163 @@
+package fsm
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+// newSessionRun is a session that logs in with l, stays active on a, and logs out with o.
+// An active session expires after 30 seconds without input, and an expired one is forgotten after 10 minutes.
+func newSessionRun(tb testing.TB, clock Clock) *TimedRun {
+	tb.Helper()
+
+	conf, err := NewBuilder().
+		Initial("anonymous").
+		Final("anonymous").
+		On("anonymous", "l", "active").
+		On("active", "a", "active").
+		On("active", "o", "anonymous").
+		On("expired", "l", "active").
+		Otherwise("anonymous", "anonymous").
+		Otherwise("active", "active").
+		Otherwise("expired", "expired").
+		Build()
+	if err != nil {
+		tb.Fatalf("session config should not have resulted in an error: %s", err)
+	}
+	fsm, err := New(*conf)
+	if err != nil {
+		tb.Fatalf("session machine should not have resulted in an error: %s", err)
+	}
+	run, err := fsm.NewTimedRun(clock, []Timeout{
+		{State: "active", After: 30 * time.Second, ResultState: "expired"},
+		{State: "expired", After: 10 * time.Minute, ResultState: "anonymous"},
+	})
+	if err != nil {
+		tb.Fatalf("session run should not have resulted in an error: %s", err)
+	}
+	return run
+}
+
+func TestTimedRun(t *testing.T) {
+	type step struct {
+		wait  time.Duration
+		input string
+	}
+
+	type test struct {
+		name          string
+		steps         []step
+		expectedState string
+	}
+
+	tests := []test{
+		{name: "no timeout in the initial state", steps: []step{{wait: time.Hour}}, expectedState: "anonymous"},
+		{name: "before the timeout", steps: []step{{input: "l"}, {wait: 29 * time.Second}}, expectedState: "active"},
+		{name: "timeout", steps: []step{{input: "l"}, {wait: 30 * time.Second}}, expectedState: "expired"},
+		{name: "input restarts the wait", steps: []step{{input: "l"}, {wait: 20 * time.Second, input: "a"}, {wait: 20 * time.Second}}, expectedState: "active"},
+		{name: "input that fails doesn't restart the wait", steps: []step{{input: "l"}, {wait: 20 * time.Second, input: "x"}, {wait: 10 * time.Second}}, expectedState: "expired"},
+		{name: "timeouts one after another", steps: []step{{input: "l"}, {wait: 30*time.Second + 10*time.Minute}}, expectedState: "anonymous"},
+		{name: "a timeout fires before input", steps: []step{{input: "l"}, {wait: time.Minute, input: "o"}}, expectedState: "expired"},
+		{name: "input after a timeout", steps: []step{{input: "l"}, {wait: time.Minute, input: "l"}, {wait: 29 * time.Second}}, expectedState: "active"},
+	}
+
+	for _, currentTest := range tests {
+		t.Run(currentTest.name, func(t *testing.T) {
+			clock := &fakeClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+			run := newSessionRun(t, clock)
+			for _, currentStep := range currentTest.steps {
+				clock.now = clock.now.Add(currentStep.wait)
+				_ = run.Feed(currentStep.input)
+			}
+			assert.Equal(t, currentTest.expectedState, run.State())
+		})
+	}
+}
+
+func TestTimedRunDeadline(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := &fakeClock{now: start}
+	run := newSessionRun(t, clock)
+
+	_, ok := run.Deadline()
+	assert.False(t, ok)
+
+	assert.Nil(t, run.Feed("l"))
+	deadline, ok := run.Deadline()
+	assert.True(t, ok)
+	assert.Equal(t, start.Add(30*time.Second), deadline)
+
+	// the expired state's wait starts when the timeout was due, not when the run next looked at the clock
+	clock.now = start.Add(time.Minute)
+	deadline, ok = run.Deadline()
+	assert.True(t, ok)
+	assert.Equal(t, start.Add(30*time.Second+10*time.Minute), deadline)
+	assert.False(t, run.Accepted())
+	assert.Equal(t, 1, run.Position())
+
+	err := run.Feed("lx")
+	assert.ErrorIs(t, err, ErrInvalidInput)
+	assert.Equal(t, "expired", run.State())
+}
+
+func TestNewTimedRunValidation(t *testing.T) {
+	fsm := newMod3Machine(t)
+
+	_, err := fsm.NewTimedRun(nil, []Timeout{
+		{State: "S3", After: time.Second, ResultState: "S0"},
+		{State: "S0", After: time.Second, ResultState: "S3"},
+		{State: "S0", After: 0, ResultState: "S1"},
+		{State: "S0", After: time.Second, ResultState: "S1"},
+		{State: "S1", After: time.Second, ResultState: "S2"},
+		{State: "S1", After: time.Minute, ResultState: "S2"},
+	})
+	var validationError *ValidationError
+	assert.ErrorAs(t, err, &validationError)
+	assert.Equal(t, []string{
+		"invalid timeout for S3:1s:S0 - invalid state",
+		"invalid timeout for S0:1s:S3 - invalid result state",
+		"invalid timeout for S0:0s:S1 - timeout must be a positive duration",
+		"invalid timeout for S1:1m0s:S2 - transition is defined more than once",
+	}, problemMessages(validationError))
+
+	run, err := fsm.NewTimedRun(nil, nil)
+	assert.Nil(t, err)
+	assert.Nil(t, run.Feed("11"))
+	assert.Equal(t, "S0", run.State())
+}
+
+func TestTimedRunTimeoutCycle(t *testing.T) {
+	conf, err := NewBuilder().Initial("p").Final("p").Otherwise("p", "p").Otherwise("q", "q").Otherwise("r", "r").Alphabet('x').Build()
+	assert.Nil(t, err)
+	fsm, err := New(*conf)
+	assert.Nil(t, err)
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := &fakeClock{now: start}
+	run, err := fsm.NewTimedRun(clock, []Timeout{
+		{State: "p", After: time.Nanosecond, ResultState: "q"},
+		{State: "q", After: 2 * time.Nanosecond, ResultState: "r"},
+		{State: "r", After: time.Nanosecond, ResultState: "q"},
+	})
+	assert.Nil(t, err)
+
+	// a long gap goes round the q, r cycle a huge number of times, but is worked out in a few steps
+	clock.now = start.Add(10*time.Second + 2*time.Nanosecond)
+	assert.Equal(t, "r", run.State())
+	deadline, ok := run.Deadline()
+	assert.True(t, ok)
+	assert.Equal(t, start.Add(10*time.Second+3*time.Nanosecond), deadline)
+
+	clock.now = clock.now.Add(time.Hour + time.Nanosecond)
+	assert.Equal(t, "q", run.State())
+}
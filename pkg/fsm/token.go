@@ -0,0 +1,248 @@
+package fsm
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// TokenTransition is a Transition taken on a whole token, such as "GET" or "200", rather than a single rune
+type TokenTransition struct {
+	State       string
+	Input       string
+	ResultState string
+}
+
+// TokenConfig is a config whose alphabet is a set of string tokens.
+// Underneath, it is a plain Config where each token is a symbol: the token at index i of Tokens() is the rune i.
+type TokenConfig struct {
+	config  *Config
+	tokens  []string
+	symbols map[string]rune
+}
+
+// NewTokenConfig builds and validates a config whose alphabet is a set of tokens.
+func NewTokenConfig(states []string, alphabet []string, initialState string, finalStates []string, transitions []TokenTransition) (*TokenConfig, error) {
+	var problems []error
+
+	tokens := slices.Clone(alphabet)
+	slices.Sort(tokens)
+	tokens = slices.Compact(tokens)
+	if len(tokens) > 0 && tokens[0] == "" {
+		problems = append(problems, ErrEmptyToken)
+		tokens = tokens[1:]
+	}
+
+	tokenConfig := &TokenConfig{
+		tokens:  tokens,
+		symbols: make(map[string]rune, len(tokens)),
+	}
+	runes := make([]rune, len(tokens))
+	for i, token := range tokens {
+		runes[i] = rune(i)
+		tokenConfig.symbols[token] = rune(i)
+	}
+
+	stateSet := make(map[string]struct{}, len(states))
+	for _, state := range states {
+		stateSet[state] = struct{}{}
+	}
+
+	// Check the transitions here, so problems name the token rather than the rune standing in for it
+	seen := make(map[string]map[string]string)
+	var runeTransitions []Transition
+	for _, transition := range transitions {
+		symbol, ok := tokenConfig.symbols[transition.Input]
+		_, knownState := stateSet[transition.State]
+		_, knownResult := stateSet[transition.ResultState]
+		var err error
+		switch {
+		case !knownState:
+			err = ErrInvalidState
+		case !ok:
+			err = ErrInvalidInput
+		case !knownResult:
+			err = ErrInvalidResultState
+		}
+		if err != nil {
+			problems = append(problems, fmt.Errorf("invalid transition for %s:%q:%s - %w", transition.State, transition.Input, transition.ResultState, err))
+			continue
+		}
+
+		if seen[transition.State] == nil {
+			seen[transition.State] = make(map[string]string)
+		}
+		if existing, ok := seen[transition.State][transition.Input]; ok {
+			if existing != transition.ResultState {
+				problems = append(problems, newProblem(ErrDuplicateTransition, fmt.Sprintf("transition for %s:%q goes to both %s and %s", transition.State, transition.Input, existing, transition.ResultState)))
+			}
+			continue
+		}
+		seen[transition.State][transition.Input] = transition.ResultState
+		runeTransitions = append(runeTransitions, Transition{State: transition.State, Input: symbol, ResultState: transition.ResultState})
+	}
+	if len(transitions) > 0 {
+		for _, state := range states {
+			for _, token := range tokens {
+				if _, ok := seen[state][token]; !ok {
+					problems = append(problems, newProblem(ErrMissingTransition, fmt.Sprintf("missing transitions for state %s for input %q", state, token)))
+				}
+			}
+		}
+	}
+
+	conf, err := NewConfig(states, runes, initialState, finalStates, runeTransitions)
+	var validationError *ValidationError
+	if errors.As(err, &validationError) {
+		for _, problem := range validationError.Problems {
+			// already reported above, by token
+			if !errors.Is(problem, ErrMissingTransition) && !(len(transitions) > 0 && errors.Is(problem, ErrEmptyTransitions)) {
+				problems = append(problems, problem)
+			}
+		}
+	} else if err != nil {
+		problems = append(problems, err)
+	}
+	if err := validationErrors(problems...); err != nil {
+		return nil, err
+	}
+
+	tokenConfig.config = conf
+	return tokenConfig, nil
+}
+
+// Config returns the plain config underneath, where the token at index i of Tokens() is the rune i.
+// Use it for analysis; inputs it works with can be turned back into tokens with TokensOf.
+func (t *TokenConfig) Config() *Config {
+	return t.config
+}
+
+// Tokens returns the alphabet of the config, sorted
+func (t *TokenConfig) Tokens() []string {
+	return slices.Clone(t.tokens)
+}
+
+// Symbol returns the rune standing in for the token in the underlying config, and false if it isn't in the alphabet
+func (t *TokenConfig) Symbol(token string) (rune, bool) {
+	symbol, ok := t.symbols[token]
+	return symbol, ok
+}
+
+// TokensOf turns an input of the underlying config back into the tokens it stands for
+func (t *TokenConfig) TokensOf(input string) []string {
+	var tokens []string
+	for _, symbol := range input {
+		if symbol >= 0 && int(symbol) < len(t.tokens) {
+			tokens = append(tokens, t.tokens[symbol])
+		}
+	}
+	return tokens
+}
+
+// TokenMachine is a machine whose alphabet is tokens, that splits raw input into tokens with a Tokenizer
+// before running it
+type TokenMachine struct {
+	config    *TokenConfig
+	tokenizer Tokenizer
+}
+
+func NewTokenMachine(config *TokenConfig, tokenizer Tokenizer) (*TokenMachine, error) {
+	if config == nil || config.config == nil {
+		return nil, ErrNilConfig
+	}
+	if tokenizer == nil {
+		return nil, ErrNilTokenizer
+	}
+	if err := config.config.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &TokenMachine{config: config, tokenizer: tokenizer}, nil
+}
+
+// Process tokenizes the input and runs the machine over the tokens, like FiniteStateMachine.Process
+func (m *TokenMachine) Process(input string) (*string, bool) {
+	state, err := m.Evaluate(input)
+	if err != nil {
+		return nil, false
+	}
+	return &state, true
+}
+
+// Evaluate tokenizes the input and runs the machine over the tokens, returning the final state.
+// If the input is rejected, the error says why; for a token that can't be processed it is a *TokenError
+// giving the token's position.
+func (m *TokenMachine) Evaluate(input string) (string, error) {
+	tokens, err := m.tokenizer.Tokenize(input)
+	if err != nil {
+		return "", err
+	}
+	return m.EvaluateTokens(tokens)
+}
+
+// ProcessTokens runs the machine over tokens that have already been split up
+func (m *TokenMachine) ProcessTokens(tokens []string) (*string, bool) {
+	positioned := make([]Token, len(tokens))
+	for i, token := range tokens {
+		positioned[i] = Token{Text: token, Index: i, Offset: -1}
+	}
+
+	state, err := m.EvaluateTokens(positioned)
+	if err != nil {
+		return nil, false
+	}
+	return &state, true
+}
+
+// EvaluateTokens is Evaluate for tokens that have already been split up
+func (m *TokenMachine) EvaluateTokens(tokens []Token) (string, error) {
+	if len(tokens) == 0 {
+		return "", ErrEmptyInput
+	}
+
+	conf := m.config.config
+	currentState := conf.initialState
+	for _, token := range tokens {
+		symbol, ok := m.config.symbols[token.Text]
+		if !ok {
+			return "", &TokenError{Token: token, Err: ErrUnknownToken}
+		}
+
+		newState, err := conf.Transitions.next(currentState, symbol)
+		if err != nil {
+			return "", &TokenError{Token: token, Err: err}
+		}
+		currentState = newState
+	}
+
+	if !conf.IsFinal(currentState) {
+		return "", fmt.Errorf("%w: ended in state %s", ErrNotAccepted, currentState)
+	}
+	return currentState, nil
+}
+
+// TokenError is a token that couldn't be processed, with its position
+type TokenError struct {
+	Token Token
+	Err   error
+}
+
+func (e *TokenError) Error() string {
+	if e.Token.Offset < 0 {
+		return fmt.Sprintf("token %d %q: %v", e.Token.Index, e.Token.Text, e.Err)
+	}
+	return fmt.Sprintf("token %d %q at byte %d: %v", e.Token.Index, e.Token.Text, e.Token.Offset, e.Err)
+}
+
+func (e *TokenError) Unwrap() error {
+	return e.Err
+}
+
+// Token is a piece of the input, with its position
+type Token struct {
+	Text string
+	// Index is the number of tokens before this one
+	Index int
+	// Offset is the byte offset of the token in the input, or -1 if it isn't known
+	Offset int
+}
@@ -0,0 +1,168 @@
+package fsm
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newRequestConfig accepts a method, then any number of status codes, then EOF
+func newRequestConfig(tb testing.TB) *TokenConfig {
+	tb.Helper()
+
+	tokens := []string{"GET", "POST", "200", "404", "EOF"}
+	var transitions []TokenTransition
+	for _, token := range tokens {
+		next := map[string]map[string]string{
+			"start":  {"GET": "method", "POST": "method"},
+			"method": {"200": "method", "404": "method", "EOF": "done"},
+		}
+		for _, state := range []string{"start", "method", "done", "error"} {
+			resultState := "error"
+			if target, ok := next[state][token]; ok {
+				resultState = target
+			}
+			transitions = append(transitions, TokenTransition{State: state, Input: token, ResultState: resultState})
+		}
+	}
+
+	conf, err := NewTokenConfig([]string{"start", "method", "done", "error"}, tokens, "start", []string{"done"}, transitions)
+	if err != nil {
+		tb.Fatalf("token config should not have resulted in an error: %s", err)
+	}
+	return conf
+}
+
+func TestTokenMachineProcess(t *testing.T) {
+	fsm, err := NewTokenMachine(newRequestConfig(t), WhitespaceTokenizer)
+	assert.Nil(t, err)
+
+	type test struct {
+		name     string
+		input    string
+		expected bool
+	}
+
+	tests := []test{
+		{name: "method then EOF", input: "GET EOF", expected: true},
+		{name: "status codes", input: "POST  200\t404\n200 EOF", expected: true},
+		{name: "no method", input: "200 EOF", expected: false},
+		{name: "no EOF", input: "GET 200", expected: false},
+		{name: "unknown token", input: "GET 500 EOF", expected: false},
+		{name: "empty", input: "   ", expected: false},
+	}
+
+	for _, currentTest := range tests {
+		t.Run(currentTest.name, func(t *testing.T) {
+			state, accepted := fsm.Process(currentTest.input)
+			assert.Equal(t, currentTest.expected, accepted)
+			if currentTest.expected {
+				assert.Equal(t, "done", *state)
+			}
+		})
+	}
+
+	state, accepted := fsm.ProcessTokens([]string{"GET", "404", "EOF"})
+	assert.True(t, accepted)
+	assert.Equal(t, "done", *state)
+}
+
+func TestTokenMachineErrors(t *testing.T) {
+	fsm, err := NewTokenMachine(newRequestConfig(t), WhitespaceTokenizer)
+	assert.Nil(t, err)
+
+	_, err = fsm.Evaluate("GET 200 500 EOF")
+	assert.ErrorIs(t, err, ErrUnknownToken)
+	assert.EqualError(t, err, `token 2 "500" at byte 8: token is not in the alphabet`)
+
+	var tokenError *TokenError
+	assert.True(t, errors.As(err, &tokenError))
+	assert.Equal(t, Token{Text: "500", Index: 2, Offset: 8}, tokenError.Token)
+
+	_, err = fsm.Evaluate("GET 200")
+	assert.ErrorIs(t, err, ErrNotAccepted)
+
+	_, err = fsm.Evaluate("")
+	assert.ErrorIs(t, err, ErrEmptyInput)
+
+	_, err = fsm.EvaluateTokens([]Token{{Text: "GET", Offset: -1}, {Text: "get", Index: 1, Offset: -1}})
+	assert.EqualError(t, err, `token 1 "get": token is not in the alphabet`)
+
+	_, err = NewTokenMachine(nil, WhitespaceTokenizer)
+	assert.ErrorIs(t, err, ErrNilConfig)
+	_, err = NewTokenMachine(newRequestConfig(t), nil)
+	assert.ErrorIs(t, err, ErrNilTokenizer)
+}
+
+func TestNewTokenConfigValidation(t *testing.T) {
+	type test struct {
+		name             string
+		alphabet         []string
+		transitions      []TokenTransition
+		expectedProblems []string
+	}
+
+	tests := []test{
+		{
+			name:     "valid",
+			alphabet: []string{"on", "off"},
+			transitions: []TokenTransition{
+				{State: "a", Input: "on", ResultState: "b"},
+				{State: "a", Input: "off", ResultState: "a"},
+				{State: "b", Input: "on", ResultState: "b"},
+				{State: "b", Input: "off", ResultState: "a"},
+			},
+		},
+		{
+			name:     "problems are reported by token",
+			alphabet: []string{"on", "off", ""},
+			transitions: []TokenTransition{
+				{State: "a", Input: "on", ResultState: "b"},
+				{State: "a", Input: "on", ResultState: "a"},
+				{State: "a", Input: "toggle", ResultState: "b"},
+				{State: "c", Input: "on", ResultState: "b"},
+				{State: "b", Input: "on", ResultState: "b"},
+				{State: "b", Input: "off", ResultState: "a"},
+			},
+			expectedProblems: []string{
+				"token cannot be empty",
+				"transition for a:\"on\" goes to both b and a",
+				"invalid transition for a:\"toggle\":b - invalid input",
+				"invalid transition for c:\"on\":b - invalid state",
+				"missing transitions for state a for input \"off\"",
+			},
+		},
+	}
+
+	for _, currentTest := range tests {
+		t.Run(currentTest.name, func(t *testing.T) {
+			conf, err := NewTokenConfig([]string{"a", "b"}, currentTest.alphabet, "a", []string{"b"}, currentTest.transitions)
+			if currentTest.expectedProblems == nil {
+				assert.Nil(t, err)
+				assert.Equal(t, []string{"off", "on"}, conf.Tokens())
+				return
+			}
+			var validationError *ValidationError
+			assert.ErrorAs(t, err, &validationError)
+			assert.Equal(t, currentTest.expectedProblems, problemMessages(validationError))
+		})
+	}
+}
+
+func TestTokenConfigUnderlyingConfig(t *testing.T) {
+	conf := newRequestConfig(t)
+
+	shortest, ok := conf.Config().ShortestAccepted()
+	assert.True(t, ok)
+	assert.Equal(t, []string{"GET", "EOF"}, conf.TokensOf(shortest))
+
+	symbol, ok := conf.Symbol("EOF")
+	assert.True(t, ok)
+	next, ok := conf.Config().Next("method", symbol)
+	assert.True(t, ok)
+	assert.Equal(t, "done", next)
+
+	_, ok = conf.Symbol("PUT")
+	assert.False(t, ok)
+}
@@ -0,0 +1,95 @@
+package fsm
+
+import (
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Tokenizer splits raw input into the tokens a TokenMachine runs over
+type Tokenizer interface {
+	Tokenize(input string) ([]Token, error)
+}
+
+// TokenizerFunc lets a plain function be used as a Tokenizer
+type TokenizerFunc func(input string) ([]Token, error)
+
+func (f TokenizerFunc) Tokenize(input string) ([]Token, error) {
+	return f(input)
+}
+
+// WhitespaceTokenizer splits the input around runs of whitespace, like strings.Fields
+var WhitespaceTokenizer Tokenizer = TokenizerFunc(func(input string) ([]Token, error) {
+	var tokens []Token
+	start := -1
+	for i, r := range input {
+		if unicode.IsSpace(r) {
+			if start >= 0 {
+				tokens = append(tokens, Token{Text: input[start:i], Index: len(tokens), Offset: start})
+				start = -1
+			}
+			continue
+		}
+		if start < 0 {
+			start = i
+		}
+	}
+	if start >= 0 {
+		tokens = append(tokens, Token{Text: input[start:], Index: len(tokens), Offset: start})
+	}
+	return tokens, nil
+})
+
+// SeparatorTokenizer splits the input around every instance of the separator, like strings.Split.
+// Empty tokens between adjacent separators are kept, and are rejected as unknown tokens.
+func SeparatorTokenizer(separator string) Tokenizer {
+	return TokenizerFunc(func(input string) ([]Token, error) {
+		if input == "" {
+			return nil, nil
+		}
+
+		var tokens []Token
+		offset := 0
+		for _, text := range strings.Split(input, separator) {
+			tokens = append(tokens, Token{Text: text, Index: len(tokens), Offset: offset})
+			offset += len(text) + len(separator)
+		}
+		return tokens, nil
+	})
+}
+
+// LongestMatchTokenizer splits input that has no separators by repeatedly taking the longest of the tokens
+// that the rest of the input starts with, skipping any whitespace between them.
+// If none of them match, the error is a *TokenError for the rune that couldn't be matched.
+func LongestMatchTokenizer(tokens []string) Tokenizer {
+	// longest first, so the first match is the longest
+	sorted := slices.Clone(tokens)
+	slices.SortStableFunc(sorted, func(a, b string) int { return len(b) - len(a) })
+
+	return TokenizerFunc(func(input string) ([]Token, error) {
+		var result []Token
+		for offset := 0; offset < len(input); {
+			r, size := utf8.DecodeRuneInString(input[offset:])
+			if unicode.IsSpace(r) {
+				offset += size
+				continue
+			}
+
+			matched := ""
+			for _, token := range sorted {
+				if token != "" && strings.HasPrefix(input[offset:], token) {
+					matched = token
+					break
+				}
+			}
+			if matched == "" {
+				return nil, &TokenError{Token: Token{Text: input[offset : offset+size], Index: len(result), Offset: offset}, Err: ErrUnknownToken}
+			}
+
+			result = append(result, Token{Text: matched, Index: len(result), Offset: offset})
+			offset += len(matched)
+		}
+		return result, nil
+	})
+}
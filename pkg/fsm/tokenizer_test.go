@@ -0,0 +1,68 @@
+package fsm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokenizers(t *testing.T) {
+	type test struct {
+		name          string
+		tokenizer     Tokenizer
+		input         string
+		expected      []Token
+		expectedError error
+	}
+
+	tests := []test{
+		{
+			name:      "whitespace",
+			tokenizer: WhitespaceTokenizer,
+			input:     " GET\t200  EOF",
+			expected:  []Token{{Text: "GET", Index: 0, Offset: 1}, {Text: "200", Index: 1, Offset: 5}, {Text: "EOF", Index: 2, Offset: 10}},
+		},
+		{
+			name:      "whitespace only",
+			tokenizer: WhitespaceTokenizer,
+			input:     " \n ",
+		},
+		{
+			name:      "separator",
+			tokenizer: SeparatorTokenizer(", "),
+			input:     "GET, 200, , EOF",
+			expected:  []Token{{Text: "GET", Index: 0, Offset: 0}, {Text: "200", Index: 1, Offset: 5}, {Text: "", Index: 2, Offset: 10}, {Text: "EOF", Index: 3, Offset: 12}},
+		},
+		{
+			name:      "separator empty input",
+			tokenizer: SeparatorTokenizer(","),
+			input:     "",
+		},
+		{
+			name:      "longest match",
+			tokenizer: LongestMatchTokenizer([]string{"<", "<=", "=", "x"}),
+			input:     "x<=x <x",
+			expected: []Token{
+				{Text: "x", Index: 0, Offset: 0}, {Text: "<=", Index: 1, Offset: 1}, {Text: "x", Index: 2, Offset: 3},
+				{Text: "<", Index: 3, Offset: 5}, {Text: "x", Index: 4, Offset: 6},
+			},
+		},
+		{
+			name:          "longest match unknown",
+			tokenizer:     LongestMatchTokenizer([]string{"<", "<=", "x"}),
+			input:         "x<é",
+			expectedError: ErrUnknownToken,
+		},
+	}
+
+	for _, currentTest := range tests {
+		t.Run(currentTest.name, func(t *testing.T) {
+			tokens, err := currentTest.tokenizer.Tokenize(currentTest.input)
+			assert.ErrorIs(t, err, currentTest.expectedError)
+			assert.Equal(t, currentTest.expected, tokens)
+		})
+	}
+
+	_, err := LongestMatchTokenizer([]string{"x"}).Tokenize("x y")
+	assert.EqualError(t, err, `token 1 "y" at byte 2: token is not in the alphabet`)
+}
@@ -2,6 +2,8 @@ package fsm
 
 import (
 	"fmt"
+	"slices"
+	"sort"
 )
 
 type Transition struct {
@@ -10,10 +12,27 @@ type Transition struct {
 	ResultState string
 }
 
+// RangeTransition is a Transition taken on any rune in any of the ranges
+type RangeTransition struct {
+	State       string
+	Inputs      []RuneRange
+	ResultState string
+}
+
 type TransitionsMap struct {
 	states      map[string]struct{}
 	alphabet    map[rune]struct{}
 	transitions map[string]map[rune]string
+
+	// Alphabets and transitions can also be given as ranges of runes, for when listing every rune isn't practical.
+	// alphabetRanges is normalized, and the ranges of each state are sorted and never overlap each other or a single rune transition.
+	alphabetRanges   []RuneRange
+	rangeTransitions map[string][]rangeTransition
+}
+
+type rangeTransition struct {
+	RuneRange
+	resultState string
 }
 
 func NewTransitionsMap(states map[string]struct{}, alphabet map[rune]struct{}) TransitionsMap {
@@ -28,15 +47,28 @@ func NewTransitionsMap(states map[string]struct{}, alphabet map[rune]struct{}) T
 		states:      states,
 		alphabet:    alphabet,
 		transitions: make(map[string]map[rune]string),
+
+		rangeTransitions: make(map[string][]rangeTransition),
 	}
 }
 
+// AddAlphabetRanges adds whole ranges of runes to the alphabet
+func (t *TransitionsMap) AddAlphabetRanges(ranges ...RuneRange) error {
+	for _, r := range ranges {
+		if r.Lo > r.Hi {
+			return fmt.Errorf("%w: %s", ErrInvalidRange, r)
+		}
+	}
+	t.alphabetRanges = normalizeRanges(append(t.alphabetRanges, ranges...))
+	return nil
+}
+
 func (t *TransitionsMap) NewTransition(transition Transition) error {
 	if _, ok := t.states[transition.State]; !ok {
 		return ErrInvalidState
 	}
 
-	if _, ok := t.alphabet[transition.Input]; !ok {
+	if !t.inAlphabet(transition.Input) {
 		return ErrInvalidInput
 	}
 
@@ -44,6 +76,10 @@ func (t *TransitionsMap) NewTransition(transition Transition) error {
 		return ErrInvalidResultState
 	}
 
+	if _, ok := t.rangeLookup(transition.State, transition.Input); ok {
+		return ErrOverlappingRange
+	}
+
 	if t.transitions[transition.State] == nil {
 		t.transitions[transition.State] = make(map[rune]string)
 	}
@@ -52,22 +88,182 @@ func (t *TransitionsMap) NewTransition(transition Transition) error {
 	return nil
 }
 
+// NewRangeTransition adds a transition on every rune of the ranges.
+// The ranges must be in the alphabet, and can't overlap any other transition from the same state,
+// so the machine stays deterministic.
+func (t *TransitionsMap) NewRangeTransition(transition RangeTransition) error {
+	if _, ok := t.states[transition.State]; !ok {
+		return ErrInvalidState
+	}
+
+	if _, ok := t.states[transition.ResultState]; !ok {
+		return ErrInvalidResultState
+	}
+
+	if len(transition.Inputs) == 0 {
+		return ErrInvalidInput
+	}
+	for _, r := range transition.Inputs {
+		if r.Lo > r.Hi {
+			return fmt.Errorf("%w: %s", ErrInvalidRange, r)
+		}
+	}
+
+	inputs := normalizeRanges(transition.Inputs)
+	existing := t.rangeTransitions[transition.State]
+	for _, r := range inputs {
+		if !t.alphabetCovers(r) {
+			return fmt.Errorf("%w: %s", ErrInvalidInput, r)
+		}
+		for input := range t.transitions[transition.State] {
+			if r.Contains(input) {
+				return fmt.Errorf("%w: %s and %c", ErrOverlappingRange, r, input)
+			}
+		}
+		for _, other := range existing {
+			if r.Lo <= other.Hi && other.Lo <= r.Hi {
+				return fmt.Errorf("%w: %s and %s", ErrOverlappingRange, r, other.RuneRange)
+			}
+		}
+	}
+
+	for _, r := range inputs {
+		existing = append(existing, rangeTransition{RuneRange: r, resultState: transition.ResultState})
+	}
+	slices.SortFunc(existing, func(a, b rangeTransition) int { return int(a.Lo) - int(b.Lo) })
+	t.rangeTransitions[transition.State] = existing
+	return nil
+}
+
 // Note: In this implementation, the transition map will be invalid if
 // there is a state that doesn't have an input set for a possible alphabet character.
 // Ex: If S1 is a State, and 'A' and 'B' are both valid inputs, but there is no (S1, 'B') mapping, it's marked as Invalid
+// Every missing (state, input) pair is reported, sorted by state and then input, in a *ValidationError.
 func (t *TransitionsMap) Validate() error {
-	for state := range t.states {
-		inputMap, ok := t.transitions[state]
-		if !ok {
-			return fmt.Errorf("missing transitions for state %s", state)
-		}
-		for input := range t.alphabet {
-			_, ok := inputMap[input]
+	states := t.sortedStates()
+	classes := t.classes()
+
+	var problems []error
+	for _, state := range states {
+		for _, class := range classes {
+			_, ok := t.lookup(state, class.Lo)
 			if !ok {
-				return fmt.Errorf("missing transitions for state %s for input %c", state, input)
+				problems = append(problems, newProblem(ErrMissingTransition, fmt.Sprintf("missing transitions for state %s for input %s", state, class)))
 			}
 		}
 	}
 
-	return nil
+	return validationErrors(problems...)
+}
+
+// next returns the state reached from state on input
+func (t *TransitionsMap) next(state string, input rune) (string, error) {
+	if !t.inAlphabet(input) {
+		return "", fmt.Errorf("%w: %q", ErrInvalidInput, input)
+	}
+
+	newState, ok := t.lookup(state, input)
+	if !ok {
+		return "", fmt.Errorf("%w: %s:%c", ErrMissingTransition, state, input)
+	}
+
+	return newState, nil
+}
+
+// sortedStates returns the states in sorted order, for anything that needs to walk them deterministically
+func (t *TransitionsMap) sortedStates() []string {
+	states := make([]string, 0, len(t.states))
+	for state := range t.states {
+		states = append(states, state)
+	}
+	sort.Strings(states)
+	return states
+}
+
+// sortedAlphabet returns the alphabet in sorted order
+func (t *TransitionsMap) sortedAlphabet() []rune {
+	alphabet := make([]rune, 0, len(t.alphabet))
+	for input := range t.alphabet {
+		alphabet = append(alphabet, input)
+	}
+	slices.Sort(alphabet)
+	return alphabet
+}
+
+// lookup returns the state reached from state on input, without checking the input is in the alphabet
+func (t *TransitionsMap) lookup(state string, input rune) (string, bool) {
+	if newState, ok := t.transitions[state][input]; ok {
+		return newState, true
+	}
+	return t.rangeLookup(state, input)
+}
+
+func (t *TransitionsMap) rangeLookup(state string, input rune) (string, bool) {
+	ranges := t.rangeTransitions[state]
+	i := sort.Search(len(ranges), func(i int) bool { return ranges[i].Hi >= input })
+	if i < len(ranges) && ranges[i].Lo <= input {
+		return ranges[i].resultState, true
+	}
+	return "", false
+}
+
+func (t *TransitionsMap) inAlphabet(input rune) bool {
+	if _, ok := t.alphabet[input]; ok {
+		return true
+	}
+	return rangesContain(t.alphabetRanges, input)
+}
+
+// alphabetCovers reports whether every rune of the range is in the alphabet
+func (t *TransitionsMap) alphabetCovers(r RuneRange) bool {
+	var singles []RuneRange
+	for input := range t.alphabet {
+		if r.Contains(input) {
+			singles = append(singles, Single(input))
+		}
+	}
+	return len(subtractRanges([]RuneRange{r}, normalizeRanges(append(singles, t.alphabetRanges...)))) == 0
+}
+
+// classes splits the alphabet into ranges of runes that every state treats the same way, sorted.
+// Anything that explores the machine only needs to try one rune of each class, and anything that counts inputs
+// can weigh that rune by the size of its class. For an alphabet of single runes, every class is a single rune.
+func (t *TransitionsMap) classes() []RuneRange {
+	if len(t.alphabetRanges) == 0 && len(t.rangeTransitions) == 0 {
+		classes := make([]RuneRange, 0, len(t.alphabet))
+		for _, input := range t.sortedAlphabet() {
+			classes = append(classes, Single(input))
+		}
+		return classes
+	}
+
+	// Every place a class could start: each single rune and the rune after it, and each range's start and the rune after its end
+	var alphabet, boundaries []RuneRange
+	for input := range t.alphabet {
+		alphabet = append(alphabet, Single(input))
+	}
+	alphabet = append(alphabet, t.alphabetRanges...)
+	for _, inputMap := range t.transitions {
+		for input := range inputMap {
+			boundaries = append(boundaries, Single(input))
+		}
+	}
+	for _, ranges := range t.rangeTransitions {
+		for _, r := range ranges {
+			boundaries = append(boundaries, r.RuneRange)
+		}
+	}
+
+	return partitionRanges(normalizeRanges(alphabet), append(boundaries, alphabet...))
+}
+
+// symbols returns one rune from each class of the alphabet, sorted.
+// For an alphabet of single runes, that is the whole alphabet.
+func (t *TransitionsMap) symbols() []rune {
+	classes := t.classes()
+	symbols := make([]rune, len(classes))
+	for i, class := range classes {
+		symbols[i] = class.Lo
+	}
+	return symbols
 }
@@ -274,3 +274,84 @@ func TestSpecialCharacterTransitions(t *testing.T) {
 	err := tm.Validate()
 	assert.Nil(t, err, "special character FSM should be valid")
 }
+
+func TestNewRangeTransitions(t *testing.T) {
+	states := map[string]struct{}{
+		"q0": {},
+		"q1": {},
+	}
+	tm := NewTransitionsMap(states, map[rune]struct{}{'_': {}})
+	assert.Nil(t, tm.AddAlphabetRanges(RuneRange{'a', 'z'}, RuneRange{'0', '9'}))
+	assert.ErrorIs(t, tm.AddAlphabetRanges(RuneRange{'z', 'a'}), ErrInvalidRange)
+
+	type test struct {
+		name          string
+		input         RangeTransition
+		expectedError error
+	}
+
+	tests := []test{
+		{
+			name:  "valid range",
+			input: RangeTransition{State: "q0", Inputs: []RuneRange{{'a', 'm'}}, ResultState: "q1"},
+		},
+		{
+			name:  "valid range made of the single runes and ranges of the alphabet",
+			input: RangeTransition{State: "q1", Inputs: []RuneRange{{'0', '9'}, Single('_')}, ResultState: "q1"},
+		},
+		{
+			name:          "overlaps a range from the same state",
+			input:         RangeTransition{State: "q0", Inputs: []RuneRange{{'m', 'z'}}, ResultState: "q0"},
+			expectedError: ErrOverlappingRange,
+		},
+		{
+			name:          "outside the alphabet",
+			input:         RangeTransition{State: "q0", Inputs: []RuneRange{{'A', 'Z'}}, ResultState: "q0"},
+			expectedError: ErrInvalidInput,
+		},
+		{
+			name:          "empty range",
+			input:         RangeTransition{State: "q0", Inputs: []RuneRange{{'z', 'n'}}, ResultState: "q0"},
+			expectedError: ErrInvalidRange,
+		},
+		{
+			name:          "no ranges",
+			input:         RangeTransition{State: "q0", ResultState: "q0"},
+			expectedError: ErrInvalidInput,
+		},
+		{
+			name:          "invalid state",
+			input:         RangeTransition{State: "q5", Inputs: []RuneRange{{'n', 'z'}}, ResultState: "q0"},
+			expectedError: ErrInvalidState,
+		},
+		{
+			name:          "invalid result",
+			input:         RangeTransition{State: "q0", Inputs: []RuneRange{{'n', 'z'}}, ResultState: "q5"},
+			expectedError: ErrInvalidResultState,
+		},
+	}
+
+	for _, currentTest := range tests {
+		err := tm.NewRangeTransition(currentTest.input)
+		if currentTest.expectedError == nil {
+			assert.Nil(t, err, currentTest.name)
+		} else {
+			assert.ErrorIs(t, err, currentTest.expectedError, currentTest.name)
+		}
+	}
+
+	// a single rune transition inside a range from the same state is ambiguous
+	assert.ErrorIs(t, tm.NewTransition(Transition{State: "q0", Input: 'c', ResultState: "q0"}), ErrOverlappingRange)
+	assert.Nil(t, tm.NewTransition(Transition{State: "q0", Input: 'n', ResultState: "q0"}))
+	assert.ErrorIs(t, tm.NewRangeTransition(RangeTransition{State: "q0", Inputs: []RuneRange{{'n', 'z'}}, ResultState: "q0"}), ErrOverlappingRange)
+
+	// q0 still has nothing for o-z, 0-9 or _, and q1 nothing for a-z
+	err := tm.Validate()
+	assert.ErrorIs(t, err, ErrMissingTransition)
+	assert.Equal(t, "missing transitions for state q0 for input 0-9; "+
+		"missing transitions for state q0 for input _; "+
+		"missing transitions for state q0 for input o-z; "+
+		"missing transitions for state q1 for input a-m; "+
+		"missing transitions for state q1 for input n; "+
+		"missing transitions for state q1 for input o-z", err.Error())
+}
@@ -0,0 +1,65 @@
+package fsm
+
+import (
+	"strings"
+)
+
+// ValidationError holds every problem found while validating a config, in a deterministic order.
+// The constructors and Validate methods of configs return one whenever anything is invalid, listing every problem
+// instead of stopping at the first. The exception is a missing piece, such as no states or an empty alphabet:
+// nothing else can be checked without it, so the problems are then just the missing pieces.
+// errors.Is and errors.As look through each of the problems, so it can be checked against the sentinel errors.
+type ValidationError struct {
+	Problems []error
+}
+
+func (v *ValidationError) Error() string {
+	messages := make([]string, len(v.Problems))
+	for i, problem := range v.Problems {
+		messages[i] = problem.Error()
+	}
+	return strings.Join(messages, "; ")
+}
+
+func (v *ValidationError) Unwrap() []error {
+	return v.Problems
+}
+
+// validationErrors returns nil if there are no problems, so callers never return a nil *ValidationError as an error.
+// Problems that are themselves ValidationErrors are flattened.
+func validationErrors(problems ...error) error {
+	var flattened []error
+	for _, problem := range problems {
+		if problem == nil {
+			continue
+		}
+		if validationError, ok := problem.(*ValidationError); ok {
+			flattened = append(flattened, validationError.Problems...)
+			continue
+		}
+		flattened = append(flattened, problem)
+	}
+
+	if len(flattened) == 0 {
+		return nil
+	}
+	return &ValidationError{Problems: flattened}
+}
+
+// problem is a validation problem with its own message, that still matches the sentinel error it is about
+type problem struct {
+	message string
+	err     error
+}
+
+func newProblem(err error, message string) error {
+	return &problem{message: message, err: err}
+}
+
+func (p *problem) Error() string {
+	return p.message
+}
+
+func (p *problem) Unwrap() error {
+	return p.err
+}
@@ -0,0 +1,82 @@
+package fsm
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewConfigCollectsAllProblems(t *testing.T) {
+	transitions := []Transition{
+		{State: "q0", Input: 'a', ResultState: "q1"},
+		{State: "q0", Input: 'c', ResultState: "q1"},
+		{State: "q5", Input: 'a', ResultState: "q1"},
+		{State: "q1", Input: 'b', ResultState: "q9"},
+	}
+
+	expected := []string{
+		"invalid transition for q0:c:q1 - invalid input",
+		"invalid transition for q5:a:q1 - invalid state",
+		"invalid transition for q1:b:q9 - invalid result state",
+		"initial state invalid: q7",
+		"q3 final state is invalid",
+		"q4 final state is invalid",
+		"missing transitions for state q0 for input b",
+		"missing transitions for state q1 for input a",
+		"missing transitions for state q1 for input b",
+	}
+
+	// map iteration order must not leak into the result
+	for range 20 {
+		_, err := NewConfig([]string{"q1", "q0"}, []rune{'b', 'a'}, "q7", []string{"q4", "q1", "q3"}, transitions)
+
+		var validationError *ValidationError
+		assert.True(t, errors.As(err, &validationError))
+		if validationError == nil {
+			t.Fatal("NewConfig should have returned a ValidationError")
+		}
+
+		messages := make([]string, len(validationError.Problems))
+		for i, problem := range validationError.Problems {
+			messages[i] = problem.Error()
+		}
+		assert.Equal(t, expected, messages)
+	}
+
+	_, err := NewConfig([]string{"q1", "q0"}, []rune{'b', 'a'}, "q7", []string{"q4", "q1", "q3"}, transitions)
+	assert.ErrorIs(t, err, ErrInvalidInput)
+	assert.ErrorIs(t, err, ErrInvalidState)
+	assert.ErrorIs(t, err, ErrInvalidResultState)
+	assert.ErrorIs(t, err, ErrInvalidInitialState)
+	assert.ErrorIs(t, err, ErrMissingTransition)
+	assert.NotErrorIs(t, err, ErrEmptyStates)
+}
+
+func TestNewConfigEmptyProblems(t *testing.T) {
+	_, err := NewConfig(nil, nil, "", nil, nil)
+
+	var validationError *ValidationError
+	assert.True(t, errors.As(err, &validationError))
+	assert.ErrorIs(t, err, ErrEmptyStates)
+	assert.ErrorIs(t, err, ErrEmptyAlphabet)
+	assert.ErrorIs(t, err, ErrEmptyInitialState)
+	assert.ErrorIs(t, err, ErrEmptyTransitions)
+	assert.ErrorIs(t, err, ErrEmptyFinalStates)
+}
+
+func TestValidateStrictCollectsAllProblems(t *testing.T) {
+	conf, err := NewConfig([]string{"q0", "q1", "sink", "orphan"}, []rune{'a'}, "q0", []string{"q1"}, []Transition{
+		{State: "q0", Input: 'a', ResultState: "q1"},
+		{State: "q1", Input: 'a', ResultState: "sink"},
+		{State: "sink", Input: 'a', ResultState: "sink"},
+		{State: "orphan", Input: 'a', ResultState: "orphan"},
+	})
+	assert.Nil(t, err)
+
+	err = conf.ValidateStrict()
+	assert.ErrorIs(t, err, ErrUnreachableState)
+	assert.ErrorIs(t, err, ErrDeadState)
+	assert.NotErrorIs(t, err, ErrEmptyLanguage)
+	assert.Equal(t, "state orphan is unreachable from initial state; state orphan can never reach a final state; state sink can never reach a final state", err.Error())
+}
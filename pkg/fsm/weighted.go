@@ -0,0 +1,304 @@
+package fsm
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+// Semiring is how weights combine: Times along a path of transitions, and Plus across the different paths an input
+// can take. Zero is the weight of no path at all, and One the weight of the empty path.
+type Semiring[W any] interface {
+	Zero() W
+	One() W
+	Plus(a W, b W) W
+	Times(a W, b W) W
+}
+
+// OutgoingValidator is implemented by semirings with rules for the weights leaving a state, such as probabilities
+// summing to one. NewWeightedConfig checks every state with it.
+type OutgoingValidator[W any] interface {
+	// ValidateOutgoing checks the weights of every transition from a state, along with its final weight if it has one
+	ValidateOutgoing(weights []W) error
+}
+
+// probabilityTolerance is how far outgoing probabilities can sum from one, for rounding errors
+const probabilityTolerance = 1e-9
+
+// ProbabilitySemiring weighs paths with probabilities: the probability of a path is the product of the probabilities
+// of its transitions, and of an input, the sum over its paths.
+// Each state's outgoing probabilities, including its final weight as the probability of stopping there, must sum to one.
+type ProbabilitySemiring struct{}
+
+func (ProbabilitySemiring) Zero() float64                      { return 0 }
+func (ProbabilitySemiring) One() float64                       { return 1 }
+func (ProbabilitySemiring) Plus(a float64, b float64) float64  { return a + b }
+func (ProbabilitySemiring) Times(a float64, b float64) float64 { return a * b }
+
+func (ProbabilitySemiring) ValidateOutgoing(weights []float64) error {
+	sum := 0.0
+	for _, weight := range weights {
+		if weight < 0 || weight > 1 || math.IsNaN(weight) {
+			return fmt.Errorf("%w: probability %g is not between 0 and 1", ErrInvalidWeight, weight)
+		}
+		sum += weight
+	}
+	if math.Abs(sum-1) > probabilityTolerance {
+		return fmt.Errorf("%w: outgoing probabilities sum to %g, not 1", ErrInvalidWeight, sum)
+	}
+	return nil
+}
+
+// TropicalSemiring weighs paths with costs: the cost of a path is the sum of the costs of its transitions,
+// and of an input, its cheapest path. An input with no path costs positive infinity.
+type TropicalSemiring struct{}
+
+func (TropicalSemiring) Zero() float64                      { return math.Inf(1) }
+func (TropicalSemiring) One() float64                       { return 0 }
+func (TropicalSemiring) Plus(a float64, b float64) float64  { return math.Min(a, b) }
+func (TropicalSemiring) Times(a float64, b float64) float64 { return a + b }
+
+// BooleanSemiring weighs paths with whether they are allowed, so the weight of an input is whether it is accepted
+type BooleanSemiring struct{}
+
+func (BooleanSemiring) Zero() bool                { return false }
+func (BooleanSemiring) One() bool                 { return true }
+func (BooleanSemiring) Plus(a bool, b bool) bool  { return a || b }
+func (BooleanSemiring) Times(a bool, b bool) bool { return a && b }
+
+// WeightedTransition is a Transition with a weight
+type WeightedTransition[W any] struct {
+	Transition
+	Weight W
+}
+
+type weightedEdge[W any] struct {
+	resultState string
+	weight      W
+}
+
+// WeightedConfig describes a weighted automaton: a machine whose transitions carry weights from a semiring, which
+// gives every input a weight, such as its probability or cost.
+//
+// Unlike a Config, transitions don't need to be complete or deterministic: a state can have no transition for an
+// input, or several to different states, and the weight of an input combines every path it can take.
+// Instead of final states, states have final weights, which end each path, and states without one end none.
+type WeightedConfig[W any] struct {
+	semiring     Semiring[W]
+	initialState string
+	finalWeights map[string]W
+	states       map[string]struct{}
+	alphabet     map[rune]struct{}
+	// transitions holds the edges from each state on each input, in the order they were given
+	transitions map[string]map[rune][]weightedEdge[W]
+}
+
+// NewWeightedConfig builds and validates a weighted config.
+func NewWeightedConfig[W any](semiring Semiring[W], states []string, alphabet []rune, initialState string, finalWeights map[string]W, transitions []WeightedTransition[W]) (*WeightedConfig[W], error) {
+	var problems []error
+
+	if semiring == nil {
+		problems = append(problems, ErrNilSemiring)
+	}
+	if len(states) == 0 {
+		problems = append(problems, ErrEmptyStates)
+	}
+	if len(alphabet) == 0 {
+		problems = append(problems, ErrEmptyAlphabet)
+	}
+	if initialState == "" {
+		problems = append(problems, ErrEmptyInitialState)
+	}
+	if len(transitions) == 0 {
+		problems = append(problems, ErrEmptyTransitions)
+	}
+	if len(finalWeights) == 0 {
+		problems = append(problems, ErrEmptyFinalStates)
+	}
+	if len(problems) > 0 {
+		return nil, validationErrors(problems...)
+	}
+
+	config := WeightedConfig[W]{
+		semiring:     semiring,
+		initialState: initialState,
+		finalWeights: make(map[string]W, len(finalWeights)),
+		states:       make(map[string]struct{}, len(states)),
+		alphabet:     make(map[rune]struct{}, len(alphabet)),
+		transitions:  make(map[string]map[rune][]weightedEdge[W]),
+	}
+	for _, state := range states {
+		if strings.TrimSpace(state) == "" {
+			problems = append(problems, ErrEmptyState)
+			continue
+		}
+		config.states[state] = struct{}{}
+	}
+	for state, weight := range finalWeights {
+		if strings.TrimSpace(state) == "" {
+			problems = append(problems, ErrEmptyFinalState)
+			continue
+		}
+		config.finalWeights[state] = weight
+	}
+	for _, input := range alphabet {
+		config.alphabet[input] = struct{}{}
+	}
+
+	for _, transition := range transitions {
+		if err := config.addTransition(transition); err != nil {
+			problems = append(problems, fmt.Errorf("invalid transition for %s:%c:%s - %w", transition.State, transition.Input, transition.ResultState, err))
+		}
+	}
+
+	problems = append(problems, config.Validate())
+	if err := validationErrors(problems...); err != nil {
+		return nil, err
+	}
+
+	return &config, nil
+}
+
+func (c *WeightedConfig[W]) addTransition(transition WeightedTransition[W]) error {
+	if _, ok := c.states[transition.State]; !ok {
+		return ErrInvalidState
+	}
+	if _, ok := c.alphabet[transition.Input]; !ok {
+		return ErrInvalidInput
+	}
+	if _, ok := c.states[transition.ResultState]; !ok {
+		return ErrInvalidResultState
+	}
+
+	byInput, ok := c.transitions[transition.State]
+	if !ok {
+		byInput = make(map[rune][]weightedEdge[W])
+		c.transitions[transition.State] = byInput
+	}
+	for _, edge := range byInput[transition.Input] {
+		if edge.resultState == transition.ResultState {
+			return ErrDuplicateTransition
+		}
+	}
+	byInput[transition.Input] = append(byInput[transition.Input], weightedEdge[W]{resultState: transition.ResultState, weight: transition.Weight})
+	return nil
+}
+
+// Validate checks the initial and final states are known, and, if the semiring is an OutgoingValidator,
+// the weights leaving every state
+func (c *WeightedConfig[W]) Validate() error {
+	var problems []error
+
+	if _, ok := c.states[c.initialState]; !ok {
+		problems = append(problems, newProblem(ErrInvalidInitialState, fmt.Sprintf("initial state invalid: %s", c.initialState)))
+	}
+	for _, finalState := range sortedSet(keySet(c.finalWeights)) {
+		if _, ok := c.states[finalState]; !ok {
+			problems = append(problems, newProblem(ErrInvalidState, fmt.Sprintf("%s final state is invalid", finalState)))
+		}
+	}
+
+	validator, ok := c.semiring.(OutgoingValidator[W])
+	if !ok {
+		return validationErrors(problems...)
+	}
+	for _, state := range sortedSet(c.states) {
+		var weights []W
+		for _, input := range c.sortedAlphabet() {
+			for _, edge := range c.transitions[state][input] {
+				weights = append(weights, edge.weight)
+			}
+		}
+		if weight, ok := c.finalWeights[state]; ok {
+			weights = append(weights, weight)
+		}
+		if err := validator.ValidateOutgoing(weights); err != nil {
+			problems = append(problems, fmt.Errorf("state %s: %w", state, err))
+		}
+	}
+	return validationErrors(problems...)
+}
+
+func (c *WeightedConfig[W]) sortedAlphabet() []rune {
+	alphabet := make([]rune, 0, len(c.alphabet))
+	for input := range c.alphabet {
+		alphabet = append(alphabet, input)
+	}
+	slices.Sort(alphabet)
+	return alphabet
+}
+
+func keySet[V any](m map[string]V) map[string]struct{} {
+	set := make(map[string]struct{}, len(m))
+	for key := range m {
+		set[key] = struct{}{}
+	}
+	return set
+}
+
+// WeightedAutomaton runs a WeightedConfig, the way FiniteStateMachine runs a Config
+type WeightedAutomaton[W any] struct {
+	Config WeightedConfig[W]
+}
+
+func NewWeighted[W any](config WeightedConfig[W]) (*WeightedAutomaton[W], error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	return &WeightedAutomaton[W]{Config: config}, nil
+}
+
+// Weight returns the weight of the input: the Plus over every path the input can take from the initial state, of
+// the Times of the weights of its transitions and the final weight of the state it ends in.
+// An input with no such path weighs the semiring's Zero. Empty input is rejected with ErrEmptyInput,
+// and input outside the alphabet with ErrInvalidInput.
+func (w *WeightedAutomaton[W]) Weight(input string) (W, error) {
+	c := &w.Config
+	semiring := c.semiring
+	if len(input) == 0 {
+		return semiring.Zero(), ErrEmptyInput
+	}
+
+	states := sortedSet(c.states)
+	current := map[string]W{c.initialState: semiring.One()}
+	position := 0
+	for i := 0; i < len(input); {
+		currentRune, size := utf8.DecodeRuneInString(input[i:])
+		if currentRune == utf8.RuneError && size <= 1 {
+			return semiring.Zero(), fmt.Errorf("position %d: %w at byte %d", position, ErrInvalidUTF8, i)
+		}
+		i += size
+		if _, ok := c.alphabet[currentRune]; !ok {
+			return semiring.Zero(), fmt.Errorf("position %d: %w: %q", position, ErrInvalidInput, currentRune)
+		}
+
+		next := make(map[string]W)
+		for _, state := range states {
+			weight, ok := current[state]
+			if !ok {
+				continue
+			}
+			for _, edge := range c.transitions[state][currentRune] {
+				pathWeight := semiring.Times(weight, edge.weight)
+				if existing, ok := next[edge.resultState]; ok {
+					pathWeight = semiring.Plus(existing, pathWeight)
+				}
+				next[edge.resultState] = pathWeight
+			}
+		}
+		current = next
+		position++
+	}
+
+	total := semiring.Zero()
+	for _, state := range states {
+		weight, ok := current[state]
+		finalWeight, final := c.finalWeights[state]
+		if ok && final {
+			total = semiring.Plus(total, semiring.Times(weight, finalWeight))
+		}
+	}
+	return total, nil
+}
@@ -0,0 +1,171 @@
+package fsm
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func weighted[W any](state string, input rune, resultState string, weight W) WeightedTransition[W] {
+	return WeightedTransition[W]{Transition: Transition{State: state, Input: input, ResultState: resultState}, Weight: weight}
+}
+
+func TestWeightedProbability(t *testing.T) {
+	// visitors moving between pages: p opens products, c the cart, b buys, and h goes home.
+	// Final weights are the probability of leaving the site from each page.
+	conf, err := NewWeightedConfig[float64](ProbabilitySemiring{}, []string{"home", "products", "cart", "bought"}, []rune{'p', 'c', 'b', 'h'}, "home",
+		map[string]float64{"home": 0.5, "products": 0.2, "cart": 0.1, "bought": 1},
+		[]WeightedTransition[float64]{
+			weighted("home", 'p', "products", 0.5),
+			weighted("products", 'c', "cart", 0.6),
+			weighted("products", 'h', "home", 0.2),
+			weighted("cart", 'b', "bought", 0.7),
+			weighted("cart", 'p', "products", 0.2),
+		})
+	assert.Nil(t, err)
+	automaton, err := NewWeighted(*conf)
+	assert.Nil(t, err)
+	if err != nil {
+		t.Fatal("navigation automaton should not have resulted in an error")
+	}
+
+	type test struct {
+		name     string
+		input    string
+		expected float64
+	}
+
+	tests := []test{
+		{name: "straight to buying", input: "pcb", expected: 0.5 * 0.6 * 0.7 * 1},
+		{name: "leaving from the products page", input: "p", expected: 0.5 * 0.2},
+		{name: "going around", input: "phpcpcb", expected: 0.5 * 0.2 * 0.5 * 0.6 * 0.2 * 0.6 * 0.7},
+		{name: "no transition", input: "c", expected: 0},
+		{name: "nothing after buying", input: "pcbh", expected: 0},
+	}
+
+	for _, currentTest := range tests {
+		t.Run(currentTest.name, func(t *testing.T) {
+			weight, err := automaton.Weight(currentTest.input)
+			assert.Nil(t, err)
+			assert.InDelta(t, currentTest.expected, weight, 1e-12)
+		})
+	}
+
+	_, err = automaton.Weight("px")
+	assert.ErrorIs(t, err, ErrInvalidInput)
+	_, err = automaton.Weight("p\xff")
+	assert.ErrorIs(t, err, ErrInvalidUTF8)
+	_, err = automaton.Weight("")
+	assert.ErrorIs(t, err, ErrEmptyInput)
+}
+
+func TestWeightedPaths(t *testing.T) {
+	// two ways from start to end on "ab": through left costs 1+5, through right costs 3+1, and only right is allowed
+	states := []string{"start", "left", "right", "end"}
+	alphabet := []rune{'a', 'b'}
+
+	tropical, err := NewWeightedConfig[float64](TropicalSemiring{}, states, alphabet, "start", map[string]float64{"end": 0.5}, []WeightedTransition[float64]{
+		weighted("start", 'a', "left", 1.0),
+		weighted("start", 'a', "right", 3.0),
+		weighted("left", 'b', "end", 5.0),
+		weighted("right", 'b', "end", 1.0),
+	})
+	assert.Nil(t, err)
+	cheapest, err := NewWeighted(*tropical)
+	assert.Nil(t, err)
+	cost, err := cheapest.Weight("ab")
+	assert.Nil(t, err)
+	assert.Equal(t, 4.5, cost)
+	cost, err = cheapest.Weight("ba")
+	assert.Nil(t, err)
+	assert.True(t, math.IsInf(cost, 1))
+
+	boolean, err := NewWeightedConfig[bool](BooleanSemiring{}, states, alphabet, "start", map[string]bool{"end": true}, []WeightedTransition[bool]{
+		weighted("start", 'a', "left", true),
+		weighted("start", 'a', "right", true),
+		weighted("left", 'b', "end", false),
+		weighted("right", 'b', "end", true),
+		weighted("end", 'a', "start", true),
+	})
+	assert.Nil(t, err)
+	allowed, err := NewWeighted(*boolean)
+	assert.Nil(t, err)
+	for input, expected := range map[string]bool{"ab": true, "aba": false, "abaab": true, "b": false} {
+		accepted, err := allowed.Weight(input)
+		assert.Nil(t, err)
+		assert.Equal(t, expected, accepted, input)
+	}
+
+	probability, err := NewWeightedConfig[float64](ProbabilitySemiring{}, states, alphabet, "start", map[string]float64{"end": 1}, []WeightedTransition[float64]{
+		weighted("start", 'a', "left", 0.25),
+		weighted("start", 'a', "right", 0.75),
+		weighted("left", 'b', "end", 1.0),
+		weighted("right", 'b', "end", 1.0),
+	})
+	assert.Nil(t, err)
+	likely, err := NewWeighted(*probability)
+	assert.Nil(t, err)
+	weight, err := likely.Weight("ab")
+	assert.Nil(t, err)
+	assert.Equal(t, 1.0, weight)
+}
+
+func TestNewWeightedConfigValidation(t *testing.T) {
+	type test struct {
+		name             string
+		finalWeights     map[string]float64
+		transitions      []WeightedTransition[float64]
+		expectedProblems []string
+	}
+
+	tests := []test{
+		{
+			name:         "invalid transitions",
+			finalWeights: map[string]float64{"a": 0.5, "b": 1},
+			transitions: []WeightedTransition[float64]{
+				weighted("a", 'x', "b", 0.5),
+				weighted("a", 'x', "b", 0.5),
+				weighted("c", 'x', "a", 1.0),
+				weighted("a", 'y', "a", 1.0),
+				weighted("a", 'x', "c", 1.0),
+			},
+			expectedProblems: []string{
+				"invalid transition for a:x:b - transition is defined more than once",
+				"invalid transition for c:x:a - invalid state",
+				"invalid transition for a:y:a - invalid input",
+				"invalid transition for a:x:c - invalid result state",
+			},
+		},
+		{
+			name:         "probabilities that don't sum to one",
+			finalWeights: map[string]float64{"a": 0.5, "c": 1},
+			transitions: []WeightedTransition[float64]{
+				weighted("a", 'x', "b", 0.25),
+				weighted("b", 'x', "a", 1.5),
+			},
+			expectedProblems: []string{
+				"c final state is invalid",
+				"state a: invalid weight: outgoing probabilities sum to 0.75, not 1",
+				"state b: invalid weight: probability 1.5 is not between 0 and 1",
+			},
+		},
+	}
+
+	for _, currentTest := range tests {
+		t.Run(currentTest.name, func(t *testing.T) {
+			_, err := NewWeightedConfig[float64](ProbabilitySemiring{}, []string{"a", "b"}, []rune{'x'}, "a", currentTest.finalWeights, currentTest.transitions)
+			var validationError *ValidationError
+			assert.ErrorAs(t, err, &validationError)
+			assert.Equal(t, currentTest.expectedProblems, problemMessages(validationError))
+		})
+	}
+
+	// other semirings have no rules for outgoing weights
+	_, err := NewWeightedConfig[float64](TropicalSemiring{}, []string{"a"}, []rune{'x'}, "a", map[string]float64{"a": 7}, []WeightedTransition[float64]{weighted("a", 'x', "a", 3.0)})
+	assert.Nil(t, err)
+
+	_, err = NewWeightedConfig[bool](nil, nil, nil, "", nil, nil)
+	assert.ErrorIs(t, err, ErrNilSemiring)
+	assert.ErrorIs(t, err, ErrEmptyStates)
+}
@@ -0,0 +1,119 @@
+package fsm
+
+// ShortestInputs returns, for every state reachable from the initial state, the shortest input that leads to it.
+// Ties are broken alphabetically, so the result is the first such input in shortlex order.
+// The initial state is reached by the empty input.
+func (c *Config) ShortestInputs() map[string]string {
+	return c.shortestInputs(false)
+}
+
+// ShortestInputTo returns the shortest input that leads from the initial state to the given state,
+// and false if the state can't be reached.
+func (c *Config) ShortestInputTo(state string) (string, bool) {
+	input, ok := c.ShortestInputs()[state]
+	return input, ok
+}
+
+// ShortestAccepted returns the shortest input the machine accepts, and false if it accepts nothing
+func (c *Config) ShortestAccepted() (string, bool) {
+	var shortest []string
+	for state, input := range c.shortestInputs(true) {
+		if _, ok := c.finalStates[state]; ok {
+			shortest = append(shortest, input)
+		}
+	}
+	return c.shortlexMin(shortest)
+}
+
+// ShortestRejected returns the shortest non-empty input over the alphabet the machine rejects,
+// and false if it accepts every one of them.
+// (The empty input is always rejected by Process, so it would be the answer to every machine otherwise.)
+func (c *Config) ShortestRejected() (string, bool) {
+	var shortest []string
+	alphabet := c.Transitions.symbols()
+
+	// a missing transition from a reachable state rejects whatever leads there, plus the missing character
+	for state, input := range c.shortestInputs(false) {
+		for _, currentRune := range alphabet {
+			if _, ok := c.Transitions.lookup(state, currentRune); !ok {
+				shortest = append(shortest, input+c.symbolString(currentRune))
+				break
+			}
+		}
+	}
+	for state, input := range c.shortestInputs(true) {
+		if _, ok := c.finalStates[state]; !ok {
+			shortest = append(shortest, input)
+		}
+	}
+
+	return c.shortlexMin(shortest)
+}
+
+// shortestInputs runs a breadth first search from the initial state, in alphabetical order of the inputs,
+// so the first time a state is seen is by its shortlex smallest input.
+// If requireInput is set, only inputs of at least one character count, so the initial state is only included
+// if it can be reached again.
+func (c *Config) shortestInputs(requireInput bool) map[string]string {
+	alphabet := c.Transitions.symbols()
+	shortest := make(map[string]string)
+
+	var queue []string
+	visit := func(state string, input string) {
+		if _, seen := shortest[state]; seen {
+			return
+		}
+		shortest[state] = input
+		queue = append(queue, state)
+	}
+	expand := func(state string, input string) {
+		for _, currentRune := range alphabet {
+			if next, ok := c.Transitions.lookup(state, currentRune); ok {
+				visit(next, input+c.symbolString(currentRune))
+			}
+		}
+	}
+
+	if requireInput {
+		expand(c.initialState, "")
+	} else {
+		visit(c.initialState, "")
+	}
+	for len(queue) > 0 {
+		state := queue[0]
+		queue = queue[1:]
+		expand(state, shortest[state])
+	}
+
+	return shortest
+}
+
+// shortlexMin returns the smallest of the inputs in shortlex order
+func (c *Config) shortlexMin(inputs []string) (string, bool) {
+	if len(inputs) == 0 {
+		return "", false
+	}
+
+	smallest := inputs[0]
+	for _, input := range inputs[1:] {
+		if c.shortlexLess(input, smallest) {
+			smallest = input
+		}
+	}
+	return smallest, true
+}
+
+// shortlexLess orders inputs by symbol count first, then alphabetically by symbol,
+// where symbols are runes in RuneMode and bytes in ByteMode
+func (c *Config) shortlexLess(a string, b string) bool {
+	aSymbols, bSymbols := c.symbolsOf(a), c.symbolsOf(b)
+	if len(aSymbols) != len(bSymbols) {
+		return len(aSymbols) < len(bSymbols)
+	}
+	for i := range aSymbols {
+		if aSymbols[i] != bSymbols[i] {
+			return aSymbols[i] < bSymbols[i]
+		}
+	}
+	return false
+}
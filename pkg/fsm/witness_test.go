@@ -0,0 +1,168 @@
+package fsm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShortestInputs(t *testing.T) {
+	conf := newMod3Config(t, "S0")
+
+	assert.Equal(t, map[string]string{"S0": "", "S1": "1", "S2": "10"}, conf.ShortestInputs())
+
+	input, ok := conf.ShortestInputTo("S2")
+	assert.True(t, ok)
+	assert.Equal(t, "10", input)
+
+	_, ok = conf.ShortestInputTo("S9")
+	assert.False(t, ok)
+
+	// every shortest input really does lead to its state
+	fsm, err := New(*conf)
+	assert.Nil(t, err)
+	for state, input := range conf.ShortestInputs() {
+		if input == "" {
+			continue
+		}
+		run := fsm.NewRun()
+		assert.Nil(t, run.Feed(input))
+		assert.Equal(t, state, run.State())
+	}
+}
+
+func TestShortestAcceptedAndRejected(t *testing.T) {
+	type test struct {
+		name             string
+		conf             *Config
+		expectedAccepted string
+		acceptsAny       bool
+		expectedRejected string
+		rejectsAny       bool
+	}
+
+	tests := []test{
+		{
+			name:             "divisible by 3",
+			conf:             newMod3Config(t, "S0"),
+			expectedAccepted: "0",
+			acceptsAny:       true,
+			expectedRejected: "1",
+			rejectsAny:       true,
+		},
+		{
+			name:             "finite language",
+			conf:             newFiniteConfig(t),
+			expectedAccepted: "b",
+			acceptsAny:       true,
+			expectedRejected: "a",
+			rejectsAny:       true,
+		},
+		{
+			name:       "accepts everything",
+			conf:       &newMod3Machine(t).Config,
+			acceptsAny: true,
+			// shortest accepted is the first character of the alphabet
+			expectedAccepted: "0",
+		},
+	}
+
+	// a machine whose only final state is the initial one, which can't be re-entered
+	nothing, err := NewConfig([]string{"q0", "q1"}, []rune{'x', 'y'}, "q0", []string{"q0"}, []Transition{
+		{State: "q0", Input: 'x', ResultState: "q1"},
+		{State: "q0", Input: 'y', ResultState: "q1"},
+		{State: "q1", Input: 'x', ResultState: "q1"},
+		{State: "q1", Input: 'y', ResultState: "q1"},
+	})
+	assert.Nil(t, err)
+	tests = append(tests, test{
+		name:             "accepts nothing",
+		conf:             nothing,
+		expectedRejected: "x",
+		rejectsAny:       true,
+	})
+
+	for _, currentTest := range tests {
+		accepted, ok := currentTest.conf.ShortestAccepted()
+		assert.Equal(t, currentTest.acceptsAny, ok, currentTest.name)
+		assert.Equal(t, currentTest.expectedAccepted, accepted, currentTest.name)
+
+		rejected, ok := currentTest.conf.ShortestRejected()
+		assert.Equal(t, currentTest.rejectsAny, ok, currentTest.name)
+		assert.Equal(t, currentTest.expectedRejected, rejected, currentTest.name)
+	}
+}
+
+func TestShortestRejectedMissingTransition(t *testing.T) {
+	// built by hand, as NewConfig would refuse the incomplete transitions
+	transitions := NewTransitionsMap(map[string]struct{}{"q0": {}, "q1": {}}, map[rune]struct{}{'a': {}, 'b': {}})
+	assert.Nil(t, transitions.NewTransition(Transition{State: "q0", Input: 'a', ResultState: "q1"}))
+	assert.Nil(t, transitions.NewTransition(Transition{State: "q0", Input: 'b', ResultState: "q1"}))
+	assert.Nil(t, transitions.NewTransition(Transition{State: "q1", Input: 'a', ResultState: "q1"}))
+	conf := Config{
+		initialState: "q0",
+		finalStates:  map[string]struct{}{"q1": {}},
+		Transitions:  transitions,
+	}
+
+	rejected, ok := conf.ShortestRejected()
+	assert.True(t, ok)
+	assert.Equal(t, "ab", rejected)
+}
+
+func TestShortestAcceptedByteMode(t *testing.T) {
+	// byteConfig accepts exactly the given inputs, byte by byte
+	byteConfig := func(accepted ...string) *Config {
+		states := []string{"start", "dead"}
+		finals := []string{}
+		alphabet := map[byte]struct{}{}
+		edges := map[string]map[byte]string{}
+		for _, input := range accepted {
+			state := "start"
+			for i := range len(input) {
+				alphabet[input[i]] = struct{}{}
+				if edges[state] == nil {
+					edges[state] = map[byte]string{}
+				}
+				next, ok := edges[state][input[i]]
+				if !ok {
+					next = state + "/" + input[i:i+1]
+					edges[state][input[i]] = next
+					states = append(states, next)
+				}
+				state = next
+			}
+			finals = append(finals, state)
+		}
+
+		var inputs []byte
+		for input := range alphabet {
+			inputs = append(inputs, input)
+		}
+		var transitions []ByteTransition
+		for _, state := range states {
+			for _, input := range inputs {
+				next, ok := edges[state][input]
+				if !ok {
+					next = "dead"
+				}
+				transitions = append(transitions, ByteTransition{State: state, Input: input, ResultState: next})
+			}
+		}
+		conf, err := NewByteConfig(states, inputs, "start", finals, transitions)
+		assert.Nil(t, err)
+		return conf
+	}
+
+	// "€" is one rune but three bytes, so in bytes "ab" is shorter
+	accepted, ok := byteConfig("€", "ab").ShortestAccepted()
+	assert.True(t, ok)
+	assert.Equal(t, "ab", accepted)
+
+	// invalid UTF-8 bytes are still told apart, rather than all counting as U+FFFD
+	for range 10 {
+		accepted, ok = byteConfig("\xff", "\xfe").ShortestAccepted()
+		assert.True(t, ok)
+		assert.Equal(t, "\xfe", accepted)
+	}
+}
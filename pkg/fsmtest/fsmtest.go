@@ -0,0 +1,186 @@
+// Package fsmtest has helpers for testing finite state machines built with the fsm package:
+// assertions on what a machine accepts, generators of random configs and inputs for property based tests,
+// the mod-three machine as a ready made fixture, and a Clock for testing timed runs without waiting.
+package fsmtest
+
+import (
+	"fmt"
+	"math/rand"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Manuel9550/FiniteStateMachine/pkg/fsm"
+)
+
+// TestingT is the part of *testing.T the helpers use
+type TestingT interface {
+	Helper()
+	Errorf(format string, args ...any)
+}
+
+// AcceptsAll checks the machine accepts every one of the inputs, reporting each one it doesn't
+func AcceptsAll(t TestingT, machine *fsm.FiniteStateMachine, inputs ...string) bool {
+	t.Helper()
+
+	ok := true
+	for _, input := range inputs {
+		if _, accepted := machine.Process(input); !accepted {
+			t.Errorf("expected input %q to be accepted", input)
+			ok = false
+		}
+	}
+	return ok
+}
+
+// RejectsAll checks the machine rejects every one of the inputs, reporting each one it doesn't
+func RejectsAll(t TestingT, machine *fsm.FiniteStateMachine, inputs ...string) bool {
+	t.Helper()
+
+	ok := true
+	for _, input := range inputs {
+		if state, accepted := machine.Process(input); accepted {
+			t.Errorf("expected input %q to be rejected, but it was accepted in state %s", input, *state)
+			ok = false
+		}
+	}
+	return ok
+}
+
+// EquivalentTo checks the two configs accept exactly the same inputs, reporting the shortest input they disagree on
+func EquivalentTo(t TestingT, got *fsm.Config, want *fsm.Config) bool {
+	t.Helper()
+
+	input, differ := got.DistinguishingInput(want)
+	if differ {
+		t.Errorf("configs are not equivalent: they disagree on input %q", input)
+		return false
+	}
+	return true
+}
+
+// ForAllInputs calls the property with count random inputs over the alphabet, of lengths 1 to maxLength,
+// and reports every input the property fails for
+func ForAllInputs(t TestingT, random *rand.Rand, alphabet []rune, maxLength int, count int, property func(input string) bool) bool {
+	t.Helper()
+
+	ok := true
+	for range count {
+		input := RandomInput(random, alphabet, 1+random.Intn(max(maxLength, 1)))
+		if !property(input) {
+			t.Errorf("property does not hold for input %q", input)
+			ok = false
+		}
+	}
+	return ok
+}
+
+// RandomInput returns a random input of the given length over the alphabet
+func RandomInput(random *rand.Rand, alphabet []rune, length int) string {
+	var builder strings.Builder
+	for range length {
+		builder.WriteRune(alphabet[random.Intn(len(alphabet))])
+	}
+	return builder.String()
+}
+
+// RandomConfig returns a random, complete config with the given number of states, named s0, s1, ..., over the alphabet.
+// The initial state is s0, and every state is final with probability one half, with at least one final state.
+// The alphabet must not be empty, as no config has an empty alphabet; RandomConfig panics if it is.
+func RandomConfig(random *rand.Rand, stateCount int, alphabet []rune) *fsm.Config {
+	if len(alphabet) == 0 {
+		panic("fsmtest: random config needs a non-empty alphabet")
+	}
+	states := make([]string, max(stateCount, 1))
+	for i := range states {
+		states[i] = fmt.Sprintf("s%d", i)
+	}
+
+	var finalStates []string
+	for _, state := range states {
+		if random.Intn(2) == 0 {
+			finalStates = append(finalStates, state)
+		}
+	}
+	if len(finalStates) == 0 {
+		finalStates = append(finalStates, states[random.Intn(len(states))])
+	}
+
+	// a rune repeated in the alphabet would get two transitions from each state
+	var inputs []rune
+	for _, input := range alphabet {
+		if !slices.Contains(inputs, input) {
+			inputs = append(inputs, input)
+		}
+	}
+
+	var transitions []fsm.Transition
+	for _, state := range states {
+		for _, input := range inputs {
+			transitions = append(transitions, fsm.Transition{
+				State:       state,
+				Input:       input,
+				ResultState: states[random.Intn(len(states))],
+			})
+		}
+	}
+
+	conf, err := fsm.NewConfig(states, alphabet, states[0], finalStates, transitions)
+	if err != nil {
+		// with the alphabet checked, every piece above is built to be valid, so this is a bug here rather than in the caller
+		panic(fmt.Sprintf("fsmtest: random config is invalid: %s", err))
+	}
+	return conf
+}
+
+// RandomMachine returns a machine for a RandomConfig
+func RandomMachine(random *rand.Rand, stateCount int, alphabet []rune) *fsm.FiniteStateMachine {
+	machine, err := fsm.New(*RandomConfig(random, stateCount, alphabet))
+	if err != nil {
+		panic(fmt.Sprintf("fsmtest: random machine is invalid: %s", err))
+	}
+	return machine
+}
+
+// Mod3Config returns the mod-three machine of the README, over binary numbers, with states S0, S1 and S2 for their
+// remainder when divided by three, and the given final states. With only S0 final, it accepts multiples of three.
+// It panics if a final state isn't one of the three.
+func Mod3Config(finalStates ...string) *fsm.Config {
+	conf, err := fsm.NewConfig([]string{"S0", "S1", "S2"}, []rune{'0', '1'}, "S0", finalStates, []fsm.Transition{
+		{State: "S0", Input: '0', ResultState: "S0"},
+		{State: "S0", Input: '1', ResultState: "S1"},
+		{State: "S1", Input: '0', ResultState: "S2"},
+		{State: "S1", Input: '1', ResultState: "S0"},
+		{State: "S2", Input: '0', ResultState: "S1"},
+		{State: "S2", Input: '1', ResultState: "S2"},
+	})
+	if err != nil {
+		panic(fmt.Sprintf("fsmtest: mod3 config is invalid: %s", err))
+	}
+	return conf
+}
+
+// Clock is an fsm.Clock that only moves when told to, so timeouts can be tested without sleeping.
+// It is safe for concurrent use.
+type Clock struct {
+	mutex sync.Mutex
+	now   time.Time
+}
+
+func NewClock(start time.Time) *Clock {
+	return &Clock{now: start}
+}
+
+func (c *Clock) Now() time.Time {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.now
+}
+
+// Advance moves the clock forward by the duration
+func (c *Clock) Advance(duration time.Duration) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.now = c.now.Add(duration)
+}
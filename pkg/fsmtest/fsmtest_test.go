@@ -0,0 +1,135 @@
+package fsmtest
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/Manuel9550/FiniteStateMachine/pkg/fsm"
+	"github.com/stretchr/testify/assert"
+)
+
+// recorder stands in for *testing.T, so failing assertions can be checked without failing the test
+type recorder struct {
+	errors []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...any) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func newEvenAs(t *testing.T) *fsm.Config {
+	conf, err := fsm.NewConfig([]string{"even", "odd"}, []rune{'a', 'b'}, "even", []string{"even"}, []fsm.Transition{
+		{State: "even", Input: 'a', ResultState: "odd"},
+		{State: "even", Input: 'b', ResultState: "even"},
+		{State: "odd", Input: 'a', ResultState: "even"},
+		{State: "odd", Input: 'b', ResultState: "odd"},
+	})
+	assert.Nil(t, err)
+	return conf
+}
+
+func TestAcceptsAndRejectsAll(t *testing.T) {
+	machine, err := fsm.New(*newEvenAs(t))
+	assert.Nil(t, err)
+
+	assert.True(t, AcceptsAll(t, machine, "aa", "b", "abab"))
+	assert.True(t, RejectsAll(t, machine, "", "a", "abaa", "c"))
+
+	failures := &recorder{}
+	assert.False(t, AcceptsAll(failures, machine, "aa", "a", "ab"))
+	assert.Equal(t, []string{`expected input "a" to be accepted`, `expected input "ab" to be accepted`}, failures.errors)
+
+	failures = &recorder{}
+	assert.False(t, RejectsAll(failures, machine, "a", "aa"))
+	assert.Equal(t, []string{`expected input "aa" to be rejected, but it was accepted in state even`}, failures.errors)
+}
+
+func TestEquivalentTo(t *testing.T) {
+	conf := newEvenAs(t)
+	assert.True(t, EquivalentTo(t, conf, conf))
+
+	oddAs, err := fsm.NewConfig([]string{"even", "odd"}, []rune{'a', 'b'}, "even", []string{"odd"}, []fsm.Transition{
+		{State: "even", Input: 'a', ResultState: "odd"},
+		{State: "even", Input: 'b', ResultState: "even"},
+		{State: "odd", Input: 'a', ResultState: "even"},
+		{State: "odd", Input: 'b', ResultState: "odd"},
+	})
+	assert.Nil(t, err)
+
+	failures := &recorder{}
+	assert.False(t, EquivalentTo(failures, conf, oddAs))
+	assert.Equal(t, []string{`configs are not equivalent: they disagree on input "a"`}, failures.errors)
+}
+
+func TestRandomConfig(t *testing.T) {
+	random := rand.New(rand.NewSource(35))
+	alphabet := []rune{'x', 'y', 'z'}
+
+	for states := 1; states <= 10; states++ {
+		conf := RandomConfig(random, states, alphabet)
+		assert.Nil(t, conf.Validate())
+
+		// a config is always equivalent to itself, and processing agrees with a fresh run
+		assert.True(t, EquivalentTo(t, conf, conf))
+		machine, err := fsm.New(*conf)
+		assert.Nil(t, err)
+		ForAllInputs(t, random, alphabet, 20, 50, func(input string) bool {
+			run := machine.NewRun()
+			if err := run.Feed(input); err != nil {
+				return false
+			}
+			_, accepted := machine.Process(input)
+			return accepted == run.Accepted()
+		})
+	}
+
+	// the same seed gives the same config
+	first := RandomConfig(rand.New(rand.NewSource(1)), 6, alphabet)
+	second := RandomConfig(rand.New(rand.NewSource(1)), 6, alphabet)
+	assert.Equal(t, first.Fingerprint(), second.Fingerprint())
+
+	// repeated runes are used once, and an empty alphabet is the caller's mistake
+	repeated := RandomConfig(random, 3, []rune{'x', 'y', 'x'})
+	assert.Equal(t, []rune{'x', 'y'}, repeated.Alphabet())
+	assert.PanicsWithValue(t, "fsmtest: random config needs a non-empty alphabet", func() {
+		RandomConfig(random, 3, nil)
+	})
+}
+
+func TestMod3Config(t *testing.T) {
+	machine, err := fsm.New(*Mod3Config("S0"))
+	assert.Nil(t, err)
+	AcceptsAll(t, machine, "0", "11", "110", "1001")
+	RejectsAll(t, machine, "1", "10", "111")
+
+	assert.Panics(t, func() { Mod3Config("S3") })
+}
+
+func TestForAllInputsReportsFailures(t *testing.T) {
+	failures := &recorder{}
+	ok := ForAllInputs(failures, rand.New(rand.NewSource(1)), []rune{'a'}, 5, 10, func(input string) bool {
+		return len(input) < 3
+	})
+	assert.False(t, ok)
+	assert.NotEmpty(t, failures.errors)
+}
+
+func TestClock(t *testing.T) {
+	machine, err := fsm.New(*newEvenAs(t))
+	assert.Nil(t, err)
+
+	clock := NewClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	run, err := machine.NewTimedRun(clock, []fsm.Timeout{{State: "odd", After: time.Minute, ResultState: "even"}})
+	assert.Nil(t, err)
+
+	assert.Nil(t, run.Feed("a"))
+	clock.Advance(59 * time.Second)
+	assert.Equal(t, "odd", run.State())
+	clock.Advance(time.Second)
+	assert.Equal(t, "even", run.State())
+	assert.Equal(t, time.Date(2024, 1, 1, 0, 1, 0, 0, time.UTC), clock.Now())
+}
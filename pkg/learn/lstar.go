@@ -0,0 +1,258 @@
+// Package learn builds fsm.Configs from observed behavior instead of writing their transitions by hand:
+// actively, by querying a black box (L*), or passively, from labeled examples.
+package learn
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/Manuel9550/FiniteStateMachine/pkg/fsm"
+)
+
+var (
+	ErrNilOracle     = errors.New("oracle cannot be nil")
+	ErrTooManyRounds = errors.New("learning did not converge")
+	// The table already agrees with the black box on a counterexample it has every suffix of
+	ErrBadCounterexample = errors.New("equivalence oracle returned an input the hypothesis gets right")
+)
+
+// LStarOptions tunes LStar. The zero value is usable.
+type LStarOptions struct {
+	// MaxRounds bounds the number of hypotheses checked against the equivalence oracle. 0 means no limit.
+	MaxRounds int
+}
+
+// LStar learns the minimal machine accepting the same inputs as a black box, using Angluin's L* algorithm.
+//
+// It keeps an observation table of the membership of prefixes followed by suffixes. Once every one-character
+// extension of a prefix behaves like some known prefix (the table is closed), the table describes a machine,
+// which is checked with the equivalence oracle. Every counterexample has all of its suffixes added to the table
+// (the Maler-Pnueli variation), which keeps the rows of distinct prefixes distinct, until the oracle is satisfied.
+//
+// The empty input is never asked about: Process never accepts it, so neither can a learned machine, and the initial
+// state is free to be final or not, whichever needs fewer states.
+// A black box that accepts nothing can't be described by a config with a reachable final state, so LStar returns
+// fsm.ErrEmptyLanguage for it.
+func LStar(alphabet []rune, membership MembershipOracle, equivalence EquivalenceOracle, options LStarOptions) (*fsm.Config, error) {
+	if len(alphabet) == 0 {
+		return nil, fsm.ErrEmptyAlphabet
+	}
+	if membership == nil || equivalence == nil {
+		return nil, ErrNilOracle
+	}
+
+	table := newObservationTable(alphabet, membership)
+	for round := 1; options.MaxRounds == 0 || round <= options.MaxRounds; round++ {
+		table.close()
+		for table.splitInitial() {
+			table.close()
+		}
+
+		hypothesis, err := table.hypothesis()
+		if err != nil {
+			return nil, err
+		}
+
+		counterexample, found := equivalence.Counterexample(hypothesis)
+		if !found {
+			if hypothesis.Analyze().EmptyLanguage {
+				return nil, fmt.Errorf("%w: the black box accepted nothing", fsm.ErrEmptyLanguage)
+			}
+			return hypothesis, nil
+		}
+		if !table.addSuffixes(counterexample) {
+			return nil, fmt.Errorf("%w: %q", ErrBadCounterexample, counterexample)
+		}
+	}
+
+	return nil, fmt.Errorf("%w after %d rounds", ErrTooManyRounds, options.MaxRounds)
+}
+
+type observationTable struct {
+	alphabet   []rune
+	membership MembershipOracle
+	cache      map[string]bool
+
+	// prefixes each have a distinct row, and are the states of the hypothesis
+	prefixes []string
+	suffixes []string
+	// rows maps a row signature back to the prefix that has it
+	rows map[string]int
+}
+
+func newObservationTable(alphabet []rune, membership MembershipOracle) *observationTable {
+	table := &observationTable{
+		alphabet:   alphabet,
+		membership: membership,
+		cache:      make(map[string]bool),
+		suffixes:   []string{""},
+	}
+	table.addPrefix("")
+	return table
+}
+
+func (o *observationTable) query(input string) bool {
+	if input == "" {
+		return false
+	}
+	if accepted, ok := o.cache[input]; ok {
+		return accepted
+	}
+	accepted := o.membership(input)
+	o.cache[input] = accepted
+	return accepted
+}
+
+// row returns the signature of the prefix: its membership with each suffix appended
+func (o *observationTable) row(prefix string) string {
+	var builder strings.Builder
+	for _, suffix := range o.suffixes {
+		if o.query(prefix + suffix) {
+			builder.WriteByte('1')
+		} else {
+			builder.WriteByte('0')
+		}
+	}
+	return builder.String()
+}
+
+func (o *observationTable) addPrefix(prefix string) {
+	o.prefixes = append(o.prefixes, prefix)
+	if o.rows == nil {
+		o.rows = make(map[string]int)
+	}
+	o.rows[o.row(prefix)] = len(o.prefixes) - 1
+}
+
+// close adds prefixes until every one-character extension of a prefix has the row of some prefix
+func (o *observationTable) close() {
+	for i := 0; i < len(o.prefixes); i++ {
+		for _, input := range o.alphabet {
+			extension := o.prefixes[i] + string(input)
+			if _, ok := o.rows[o.row(extension)]; !ok {
+				o.addPrefix(extension)
+			}
+		}
+	}
+}
+
+// addSuffixes adds every suffix of the counterexample as a column, which splits at least one row.
+// It returns false if there was nothing new to add.
+func (o *observationTable) addSuffixes(counterexample string) bool {
+	known := make(map[string]struct{}, len(o.suffixes))
+	for _, suffix := range o.suffixes {
+		known[suffix] = struct{}{}
+	}
+
+	added := false
+	runes := []rune(counterexample)
+	for i := range runes {
+		suffix := string(runes[i:])
+		if _, ok := known[suffix]; !ok {
+			known[suffix] = struct{}{}
+			o.suffixes = append(o.suffixes, suffix)
+			added = true
+		}
+	}
+	if added {
+		o.reindex()
+	}
+	return added
+}
+
+// reindex recomputes the rows after columns are added.
+// Adding columns can only tell rows apart, never merge them, so the prefixes all stay distinct.
+func (o *observationTable) reindex() {
+	o.rows = make(map[string]int, len(o.prefixes))
+	for i, prefix := range o.prefixes {
+		o.rows[o.row(prefix)] = i
+	}
+}
+
+// twin returns the prefix whose row is the empty prefix's, except for the empty suffix.
+// The empty prefix's answer for the empty suffix means nothing, as Process never accepts the empty input,
+// so the initial state can be its twin's state, unless the table shows them behaving differently later on.
+func (o *observationTable) twin() (int, bool) {
+	initial := []byte(o.row(""))
+	// the empty suffix is always the first column, and the empty input is never accepted
+	initial[0] = '1'
+	i, ok := o.rows[string(initial)]
+	return i, ok
+}
+
+// splitInitial checks the empty prefix goes where its twin does on every input, and if not, adds the column that
+// tells them apart. It returns false if there was nothing to add.
+func (o *observationTable) splitInitial() bool {
+	twin, ok := o.twin()
+	if !ok {
+		return false
+	}
+
+	for _, input := range o.alphabet {
+		for _, suffix := range o.suffixes {
+			extended := string(input) + suffix
+			if o.query(extended) != o.query(o.prefixes[twin]+extended) {
+				// every suffix of the new column is already a column, so the suffixes stay suffix closed
+				o.suffixes = append(o.suffixes, extended)
+				o.reindex()
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// hypothesis turns a closed table into a config, with one state per prefix.
+// The empty prefix shares its twin's state instead if it has one and nothing leads back to the empty prefix.
+func (o *observationTable) hypothesis() (*fsm.Config, error) {
+	targets := make([][]int, len(o.prefixes))
+	entered := false
+	for i, prefix := range o.prefixes {
+		for _, input := range o.alphabet {
+			target := o.rows[o.row(prefix+string(input))]
+			targets[i] = append(targets[i], target)
+			entered = entered || target == 0
+		}
+	}
+	initial := 0
+	if twin, ok := o.twin(); ok && !entered {
+		initial = twin
+	}
+
+	// with the empty prefix merged away, the states are renumbered to start from q0 again
+	stateName := func(i int) string {
+		if initial != 0 {
+			i--
+		}
+		return fmt.Sprintf("q%d", i)
+	}
+
+	var states, finalStates []string
+	var transitions []fsm.Transition
+	for i, prefix := range o.prefixes {
+		if i == 0 && initial != 0 {
+			continue
+		}
+		states = append(states, stateName(i))
+		if o.query(prefix) {
+			finalStates = append(finalStates, stateName(i))
+		}
+		for j, input := range o.alphabet {
+			transitions = append(transitions, fsm.Transition{State: stateName(i), Input: input, ResultState: stateName(targets[i][j])})
+		}
+	}
+
+	// A config needs a final state, so a table that accepts nothing gets one that can never be reached.
+	// The empty prefix is never merged then, as its twin would be final.
+	if len(finalStates) == 0 {
+		unreachable := stateName(len(states))
+		states = append(states, unreachable)
+		finalStates = append(finalStates, unreachable)
+		for _, input := range o.alphabet {
+			transitions = append(transitions, fsm.Transition{State: unreachable, Input: input, ResultState: unreachable})
+		}
+	}
+
+	return fsm.NewConfig(states, o.alphabet, stateName(initial), finalStates, transitions)
+}
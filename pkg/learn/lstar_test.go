@@ -0,0 +1,122 @@
+package learn
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/Manuel9550/FiniteStateMachine/pkg/fsm"
+	"github.com/Manuel9550/FiniteStateMachine/pkg/fsmtest"
+	"github.com/stretchr/testify/assert"
+)
+
+func membershipOf(t *testing.T, conf *fsm.Config) MembershipOracle {
+	machine, err := fsm.New(*conf)
+	assert.Nil(t, err)
+	return func(input string) bool {
+		_, accepted := machine.Process(input)
+		return accepted
+	}
+}
+
+func TestLStarLearnsMod3(t *testing.T) {
+	// only S0 final: binary numbers divisible by three
+	target := fsmtest.Mod3Config("S0")
+	membership := membershipOf(t, target)
+
+	oracle := &RandomTestingOracle{
+		Membership: membership,
+		Alphabet:   target.Alphabet(),
+		Tests:      2000,
+		MaxLength:  30,
+		Random:     rand.New(rand.NewSource(36)),
+	}
+
+	learned, err := LStar(target.Alphabet(), membership, oracle, LStarOptions{})
+	assert.Nil(t, err)
+	if err != nil {
+		t.Fatal("learning should not have resulted in an error")
+	}
+
+	input, differ := learned.DistinguishingInput(target)
+	assert.False(t, differ, "learned machine disagrees on %q", input)
+	assert.Equal(t, len(target.States()), len(learned.States()))
+	assert.Nil(t, learned.ValidateStrict())
+}
+
+func TestLStarLearnsMod3ProcessExactly(t *testing.T) {
+	// all states final, as in the mod3 example: every non-empty binary string is accepted
+	target := fsmtest.Mod3Config("S0", "S1", "S2")
+
+	learned, err := LStar(target.Alphabet(), membershipOf(t, target), ConfigOracle(target), LStarOptions{})
+	assert.Nil(t, err)
+	assert.True(t, learned.Equivalent(target))
+	// a single final state is enough, as the empty input it would accept is never processed
+	assert.Equal(t, 1, len(learned.States()))
+}
+
+func TestLStarSplitsInitialState(t *testing.T) {
+	// inputs starting with a: the empty prefix looks like "a" until the table sees where b leads from each
+	target, err := fsm.NewBuilder().
+		Initial("start").
+		Final("yes").
+		On("start", "a", "yes").
+		On("start", "b", "no").
+		On("yes", "ab", "yes").
+		On("no", "ab", "no").
+		Build()
+	assert.Nil(t, err)
+
+	learned, err := LStar(target.Alphabet(), membershipOf(t, target), ConfigOracle(target), LStarOptions{})
+	assert.Nil(t, err)
+	assert.True(t, learned.Equivalent(target))
+	assert.Equal(t, len(target.States()), len(learned.States()))
+}
+
+func TestLStarNeedsCounterexamples(t *testing.T) {
+	// inputs at least five long: the first table sees nothing accepted, so the oracle has to push it along
+	alphabet := []rune{'a', 'b'}
+	membership := func(input string) bool {
+		return len(input) >= 5
+	}
+	exact := EquivalenceOracleFunc(func(hypothesis *fsm.Config) (string, bool) {
+		machine, err := fsm.New(*hypothesis)
+		assert.Nil(t, err)
+		for length := 1; length <= 8; length++ {
+			input := strings.Repeat("a", length)
+			if _, accepted := machine.Process(input); accepted != membership(input) {
+				return input, true
+			}
+		}
+		return "", false
+	})
+
+	learned, err := LStar(alphabet, membership, exact, LStarOptions{})
+	assert.Nil(t, err)
+	assert.Equal(t, 6, len(learned.States()))
+	assert.Equal(t, int64(32), learned.CountAccepted(5).Int64())
+	assert.Equal(t, int64(0), learned.CountAccepted(4).Int64())
+}
+
+func TestLStarErrors(t *testing.T) {
+	membership := func(input string) bool { return true }
+	never := EquivalenceOracleFunc(func(hypothesis *fsm.Config) (string, bool) { return "", false })
+	always := EquivalenceOracleFunc(func(hypothesis *fsm.Config) (string, bool) { return "a", true })
+
+	_, err := LStar(nil, membership, never, LStarOptions{})
+	assert.ErrorIs(t, err, fsm.ErrEmptyAlphabet)
+
+	_, err = LStar([]rune{'a'}, nil, never, LStarOptions{})
+	assert.ErrorIs(t, err, ErrNilOracle)
+
+	_, err = LStar([]rune{'a'}, membership, always, LStarOptions{MaxRounds: 1})
+	assert.ErrorIs(t, err, ErrTooManyRounds)
+
+	// "a" is already in the table after the first round, so it can't be a counterexample a second time
+	_, err = LStar([]rune{'a'}, membership, always, LStarOptions{})
+	assert.ErrorIs(t, err, ErrBadCounterexample)
+
+	// no config with a reachable final state accepts nothing
+	_, err = LStar([]rune{'a'}, func(string) bool { return false }, never, LStarOptions{})
+	assert.ErrorIs(t, err, fsm.ErrEmptyLanguage)
+}
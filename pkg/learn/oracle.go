@@ -0,0 +1,66 @@
+package learn
+
+import (
+	"math/rand"
+	"strings"
+
+	"github.com/Manuel9550/FiniteStateMachine/pkg/fsm"
+)
+
+// MembershipOracle answers whether the system being learned accepts the input
+type MembershipOracle func(input string) bool
+
+// EquivalenceOracle checks a hypothesis against the system being learned.
+// It returns an input the hypothesis gets wrong, or false if it can't find one.
+type EquivalenceOracle interface {
+	Counterexample(hypothesis *fsm.Config) (string, bool)
+}
+
+// EquivalenceOracleFunc lets an ordinary function be used as an EquivalenceOracle
+type EquivalenceOracleFunc func(hypothesis *fsm.Config) (string, bool)
+
+func (e EquivalenceOracleFunc) Counterexample(hypothesis *fsm.Config) (string, bool) {
+	return e(hypothesis)
+}
+
+// RandomTestingOracle is an EquivalenceOracle for black boxes: it compares the hypothesis against the membership
+// oracle on random inputs. It can only ever find differences, never prove there are none.
+type RandomTestingOracle struct {
+	Membership MembershipOracle
+	Alphabet   []rune
+	// Tests is the number of random inputs tried per hypothesis
+	Tests int
+	// MaxLength is the longest random input tried
+	MaxLength int
+	Random    *rand.Rand
+}
+
+func (r *RandomTestingOracle) Counterexample(hypothesis *fsm.Config) (string, bool) {
+	machine, err := fsm.New(*hypothesis)
+	if err != nil {
+		return "", false
+	}
+
+	var builder strings.Builder
+	for range r.Tests {
+		builder.Reset()
+		length := 1 + r.Random.Intn(max(r.MaxLength, 1))
+		for range length {
+			builder.WriteRune(r.Alphabet[r.Random.Intn(len(r.Alphabet))])
+		}
+
+		input := builder.String()
+		if _, accepted := machine.Process(input); accepted != r.Membership(input) {
+			return input, true
+		}
+	}
+
+	return "", false
+}
+
+// ConfigOracle is an exact EquivalenceOracle for when the target machine is known, such as in tests
+func ConfigOracle(target *fsm.Config) EquivalenceOracle {
+	return EquivalenceOracleFunc(func(hypothesis *fsm.Config) (string, bool) {
+		return hypothesis.DistinguishingInput(target)
+	})
+}
@@ -0,0 +1,293 @@
+package learn
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/Manuel9550/FiniteStateMachine/pkg/fsm"
+)
+
+var (
+	ErrNoPositiveExamples   = errors.New("must have some positive examples")
+	ErrInconsistentExamples = errors.New("examples contradict each other")
+	ErrExampleNotInAlphabet = errors.New("example uses a character outside the alphabet")
+)
+
+const (
+	labelUnknown = iota
+	labelAccept
+	labelReject
+)
+
+// prefixTree is a machine under construction, with states numbered from 0 (the initial state).
+// It starts out as a tree, and merging states folds it into a graph.
+type prefixTree struct {
+	alphabet    []rune
+	transitions []map[rune]int
+	labels      []int
+}
+
+// RPNI learns a small machine that accepts every positive example and rejects every negative one.
+//
+// It builds a prefix tree of the examples, then walks its states in shortlex order, merging each one into the
+// first already kept state it can be merged with without making the tree accept a negative example or reject
+// a positive one (Regular Positive and Negative Inference). The more representative the examples, the closer the
+// result is to the machine they came from.
+//
+// If alphabet is nil, it is every character used in the examples.
+// The empty input is always rejected, as Process never accepts it, so it can't be a positive example, and as a
+// negative example it is ignored. That leaves the initial state free to be merged with final states.
+func RPNI(alphabet []rune, positive []string, negative []string) (*fsm.Config, error) {
+	if len(positive) == 0 {
+		return nil, ErrNoPositiveExamples
+	}
+
+	if alphabet == nil {
+		seen := make(map[rune]struct{})
+		for _, example := range slices.Concat(positive, negative) {
+			for _, input := range example {
+				if _, ok := seen[input]; !ok {
+					seen[input] = struct{}{}
+					alphabet = append(alphabet, input)
+				}
+			}
+		}
+	}
+	alphabet = slices.Clone(alphabet)
+	slices.Sort(alphabet)
+	alphabet = slices.Compact(alphabet)
+
+	tree, err := newPrefixTree(alphabet, positive, negative)
+	if err != nil {
+		return nil, err
+	}
+
+	red := []int{0}
+	for {
+		blue, parent, input, ok := tree.firstBlue(red)
+		if !ok {
+			break
+		}
+
+		merged := false
+		for _, redState := range red {
+			candidate := tree.clone()
+			candidate.transitions[parent][input] = redState
+			if candidate.fold(redState, blue) {
+				tree = candidate
+				merged = true
+				break
+			}
+		}
+		if !merged {
+			red = append(red, blue)
+		}
+	}
+
+	return tree.config()
+}
+
+func newPrefixTree(alphabet []rune, positive []string, negative []string) (*prefixTree, error) {
+	tree := &prefixTree{
+		alphabet:    alphabet,
+		transitions: []map[rune]int{{}},
+		// Process never accepts the empty input, so whether the initial state is final doesn't matter
+		labels: []int{labelUnknown},
+	}
+
+	add := func(example string, label int) error {
+		if example == "" && label == labelAccept {
+			return fmt.Errorf("%w: the empty input is positive, but is never accepted", ErrInconsistentExamples)
+		}
+		if example == "" {
+			return nil
+		}
+		state := 0
+		for _, input := range example {
+			if _, ok := slices.BinarySearch(alphabet, input); !ok {
+				return fmt.Errorf("%w: %q in %q", ErrExampleNotInAlphabet, input, example)
+			}
+			next, ok := tree.transitions[state][input]
+			if !ok {
+				next = len(tree.labels)
+				tree.transitions = append(tree.transitions, map[rune]int{})
+				tree.labels = append(tree.labels, labelUnknown)
+				tree.transitions[state][input] = next
+			}
+			state = next
+		}
+
+		if tree.labels[state] != labelUnknown && tree.labels[state] != label {
+			return fmt.Errorf("%w: %q is both positive and negative", ErrInconsistentExamples, example)
+		}
+		tree.labels[state] = label
+		return nil
+	}
+
+	for _, example := range positive {
+		if err := add(example, labelAccept); err != nil {
+			return nil, err
+		}
+	}
+	for _, example := range negative {
+		if err := add(example, labelReject); err != nil {
+			return nil, err
+		}
+	}
+
+	// Renumber the states in breadth first order, so that walking them in order is walking them in shortlex order
+	return tree.renumber(), nil
+}
+
+// renumber returns the reachable part of the tree, with states numbered in breadth first order
+func (p *prefixTree) renumber() *prefixTree {
+	order := p.reachable()
+	numbers := make(map[int]int, len(order))
+	for i, state := range order {
+		numbers[state] = i
+	}
+
+	renumbered := &prefixTree{
+		alphabet:    p.alphabet,
+		transitions: make([]map[rune]int, len(order)),
+		labels:      make([]int, len(order)),
+	}
+	for i, state := range order {
+		renumbered.labels[i] = p.labels[state]
+		renumbered.transitions[i] = make(map[rune]int, len(p.transitions[state]))
+		for input, next := range p.transitions[state] {
+			renumbered.transitions[i][input] = numbers[next]
+		}
+	}
+	return renumbered
+}
+
+// reachable returns the states reachable from the initial state, in breadth first order over the sorted alphabet
+func (p *prefixTree) reachable() []int {
+	seen := map[int]struct{}{0: {}}
+	order := []int{0}
+	for i := 0; i < len(order); i++ {
+		for _, input := range p.alphabet {
+			next, ok := p.transitions[order[i]][input]
+			if !ok {
+				continue
+			}
+			if _, visited := seen[next]; !visited {
+				seen[next] = struct{}{}
+				order = append(order, next)
+			}
+		}
+	}
+	return order
+}
+
+// firstBlue finds the smallest state that isn't red but is the target of a transition from a red state,
+// along with that transition. Blue states are always roots of untouched subtrees, so the transition is their only one in.
+func (p *prefixTree) firstBlue(red []int) (int, int, rune, bool) {
+	isRed := make(map[int]struct{}, len(red))
+	for _, state := range red {
+		isRed[state] = struct{}{}
+	}
+
+	blue, parent, blueInput, found := 0, 0, rune(0), false
+	for _, state := range red {
+		for _, input := range p.alphabet {
+			next, ok := p.transitions[state][input]
+			if !ok {
+				continue
+			}
+			if _, ok := isRed[next]; ok {
+				continue
+			}
+			if !found || next < blue {
+				blue, parent, blueInput, found = next, state, input, true
+			}
+		}
+	}
+	return blue, parent, blueInput, found
+}
+
+func (p *prefixTree) clone() *prefixTree {
+	cloned := &prefixTree{
+		alphabet:    p.alphabet,
+		transitions: make([]map[rune]int, len(p.transitions)),
+		labels:      slices.Clone(p.labels),
+	}
+	for i, transitions := range p.transitions {
+		cloned.transitions[i] = make(map[rune]int, len(transitions))
+		for input, next := range transitions {
+			cloned.transitions[i][input] = next
+		}
+	}
+	return cloned
+}
+
+// fold merges the subtree rooted at from into target, returning false if that would contradict an example
+func (p *prefixTree) fold(target int, from int) bool {
+	if target == from {
+		return true
+	}
+
+	switch {
+	case p.labels[from] == labelUnknown:
+	case p.labels[target] == labelUnknown:
+		p.labels[target] = p.labels[from]
+	case p.labels[target] != p.labels[from]:
+		return false
+	}
+
+	for _, input := range p.alphabet {
+		fromNext, ok := p.transitions[from][input]
+		if !ok {
+			continue
+		}
+		targetNext, ok := p.transitions[target][input]
+		if !ok {
+			p.transitions[target][input] = fromNext
+			continue
+		}
+		if !p.fold(targetNext, fromNext) {
+			return false
+		}
+	}
+	return true
+}
+
+// config turns the learned machine into a config. Inputs the examples never reached go to a rejecting sink state.
+func (p *prefixTree) config() (*fsm.Config, error) {
+	order := p.reachable()
+	names := make(map[int]string, len(order))
+	for i, state := range order {
+		names[state] = fmt.Sprintf("q%d", i)
+	}
+	sink := fmt.Sprintf("q%d", len(order))
+
+	var states, finalStates []string
+	var transitions []fsm.Transition
+	needsSink := false
+	for _, state := range order {
+		states = append(states, names[state])
+		if p.labels[state] == labelAccept {
+			finalStates = append(finalStates, names[state])
+		}
+		for _, input := range p.alphabet {
+			resultState := sink
+			if next, ok := p.transitions[state][input]; ok {
+				resultState = names[next]
+			} else {
+				needsSink = true
+			}
+			transitions = append(transitions, fsm.Transition{State: names[state], Input: input, ResultState: resultState})
+		}
+	}
+
+	if needsSink {
+		states = append(states, sink)
+		for _, input := range p.alphabet {
+			transitions = append(transitions, fsm.Transition{State: sink, Input: input, ResultState: sink})
+		}
+	}
+
+	return fsm.NewConfig(states, p.alphabet, names[0], finalStates, transitions)
+}
@@ -0,0 +1,87 @@
+package learn
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/Manuel9550/FiniteStateMachine/pkg/fsm"
+	"github.com/Manuel9550/FiniteStateMachine/pkg/fsmtest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRPNILearnsMod3(t *testing.T) {
+	target := fsmtest.Mod3Config("S0")
+	membership := membershipOf(t, target)
+
+	// every binary string up to 6 long is a characteristic sample for such a small machine
+	var positive, negative []string
+	for length := 1; length <= 6; length++ {
+		for value := range 1 << length {
+			example := fmt.Sprintf("%0*b", length, value)
+			if membership(example) {
+				positive = append(positive, example)
+			} else {
+				negative = append(negative, example)
+			}
+		}
+	}
+
+	learned, err := RPNI(nil, positive, negative)
+	assert.Nil(t, err)
+	if err != nil {
+		t.Fatal("learning should not have resulted in an error")
+	}
+
+	input, differ := learned.DistinguishingInput(target)
+	assert.False(t, differ, "learned machine disagrees on %q", input)
+	assert.Equal(t, len(target.States()), len(learned.States()))
+}
+
+func TestRPNIConsistentWithExamples(t *testing.T) {
+	positive := []string{"GET", "GETS", "PUT"}
+	negative := []string{"GE", "PU", "P", "GETT", "PUTS"}
+
+	learned, err := RPNI(nil, positive, negative)
+	assert.Nil(t, err)
+
+	machine, err := fsm.New(*learned)
+	assert.Nil(t, err)
+	for _, example := range positive {
+		_, accepted := machine.Process(example)
+		assert.True(t, accepted, example)
+	}
+	for _, example := range negative {
+		_, accepted := machine.Process(example)
+		assert.False(t, accepted, example)
+	}
+	assert.Equal(t, []rune("EGPSTU"), learned.Alphabet())
+}
+
+func TestRPNIGeneralizes(t *testing.T) {
+	// a+ from a few examples: one accepting loop
+	learned, err := RPNI([]rune{'a', 'b'}, []string{"a", "aa", "aaa"}, []string{"", "b", "ab", "ba"})
+	assert.Nil(t, err)
+	// the accepting loop is the initial state itself, as the empty input is never processed
+	assert.True(t, learned.IsFinal(learned.InitialState()))
+
+	machine, err := fsm.New(*learned)
+	assert.Nil(t, err)
+	_, accepted := machine.Process("aaaaaaaa")
+	assert.True(t, accepted)
+	_, accepted = machine.Process("aaaab")
+	assert.False(t, accepted)
+}
+
+func TestRPNIErrors(t *testing.T) {
+	_, err := RPNI(nil, nil, []string{"a"})
+	assert.ErrorIs(t, err, ErrNoPositiveExamples)
+
+	_, err = RPNI(nil, []string{"a", "b"}, []string{"b"})
+	assert.ErrorIs(t, err, ErrInconsistentExamples)
+
+	_, err = RPNI(nil, []string{""}, nil)
+	assert.ErrorIs(t, err, ErrInconsistentExamples)
+
+	_, err = RPNI([]rune{'a'}, []string{"ab"}, nil)
+	assert.ErrorIs(t, err, ErrExampleNotInAlphabet)
+}
@@ -0,0 +1,301 @@
+// Package lex generates lexers from fsm.Configs: one config per token type, combined into a single machine
+// that splits input into tokens by longest match.
+package lex
+
+import (
+	"errors"
+	"fmt"
+	"iter"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/Manuel9550/FiniteStateMachine/pkg/fsm"
+)
+
+var (
+	ErrNoRules       = errors.New("must have some rules")
+	ErrEmptyRuleName = errors.New("rule name cannot be empty")
+	ErrDuplicateRule = errors.New("rule name is used more than once")
+	ErrNilConfig     = errors.New("rule config cannot be nil")
+	ErrByteMode      = errors.New("rule config must read runes, not bytes")
+	ErrNoMatch       = errors.New("no rule matches the input")
+)
+
+// Rule is a token type: the inputs its config accepts are tokens of that type
+type Rule struct {
+	Name   string
+	Config *fsm.Config
+	// Priority decides between rules that match the same longest text, such as a keyword and an identifier.
+	// The higher priority wins, and between equal priorities, the rule listed first.
+	Priority int
+	// Skip rules, such as whitespace and comments, are matched like any other but not returned as tokens
+	Skip bool
+}
+
+// Token is a piece of the input matched by a rule
+type Token struct {
+	Type string
+	Text string
+	// Offset is the byte offset of the token in the input
+	Offset int
+	// Line and Column are where the token starts, counting from 1, with columns counted in runes
+	Line   int
+	Column int
+}
+
+// Error is input no rule matches
+type Error struct {
+	Offset int
+	Line   int
+	Column int
+	// Text is the rune no token could start with, or the invalid UTF-8 byte
+	Text string
+	Err  error
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("line %d column %d (byte %d): %v: %q", e.Line, e.Column, e.Offset, e.Err, e.Text)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Lexer splits input into tokens. It is safe for concurrent use.
+type Lexer struct {
+	rules   []Rule
+	machine *fsm.Config
+	// accepts maps each final state of the machine to the index of the rule it matches
+	accepts map[string]int
+}
+
+// New combines the rules into a single product machine, whose states track every rule's config at once,
+// and labels each of its final states with the rule that wins there.
+// Invalid rules are reported together as a *fsm.ValidationError, like invalid configs.
+func New(rules []Rule) (*Lexer, error) {
+	if len(rules) == 0 {
+		return nil, &fsm.ValidationError{Problems: []error{ErrNoRules}}
+	}
+
+	var problems []error
+	names := make(map[string]struct{}, len(rules))
+	for i, rule := range rules {
+		switch {
+		case rule.Name == "":
+			problems = append(problems, fmt.Errorf("rule %d: %w", i, ErrEmptyRuleName))
+		case rule.Config == nil:
+			problems = append(problems, fmt.Errorf("rule %s: %w", rule.Name, ErrNilConfig))
+		case rule.Config.Mode() != fsm.RuneMode:
+			problems = append(problems, fmt.Errorf("rule %s: %w", rule.Name, ErrByteMode))
+		}
+		if _, ok := names[rule.Name]; ok && rule.Name != "" {
+			problems = append(problems, fmt.Errorf("rule %s: %w", rule.Name, ErrDuplicateRule))
+		}
+		names[rule.Name] = struct{}{}
+	}
+	if len(problems) > 0 {
+		return nil, &fsm.ValidationError{Problems: problems}
+	}
+
+	lexer := &Lexer{rules: slices.Clone(rules)}
+	if err := lexer.build(); err != nil {
+		return nil, err
+	}
+	return lexer, nil
+}
+
+// Machine returns the product machine, whose final states are the places a token can end
+func (l *Lexer) Machine() *fsm.Config {
+	return l.machine
+}
+
+// RuleOf returns the name of the rule that matches when the machine stops in the state, and false if it's not final
+func (l *Lexer) RuleOf(state string) (string, bool) {
+	rule, ok := l.accepts[state]
+	if !ok {
+		return "", false
+	}
+	return l.rules[rule].Name, true
+}
+
+// Tokenize splits the whole input into tokens, leaving out skipped ones.
+// If some of the input can't be matched, the tokens before it are returned along with an *Error.
+func (l *Lexer) Tokenize(input string) ([]Token, error) {
+	var tokens []Token
+	for token, err := range l.Tokens(input) {
+		if err != nil {
+			return tokens, err
+		}
+		tokens = append(tokens, token)
+	}
+	return tokens, nil
+}
+
+// Tokens yields the tokens of the input one at a time, leaving out skipped ones.
+// Each token is the longest prefix of the remaining input any rule matches (maximal munch).
+// If some of the input can't be matched, an *Error is yielded and the sequence ends.
+func (l *Lexer) Tokens(input string) iter.Seq2[Token, error] {
+	return func(yield func(Token, error) bool) {
+		line, column := 1, 1
+		for offset := 0; offset < len(input); {
+			end, rule, ok := l.longestMatch(input, offset)
+			if !ok {
+				text := input[offset:]
+				if _, size := utf8.DecodeRuneInString(text); size > 0 {
+					text = text[:size]
+				}
+				yield(Token{}, &Error{Offset: offset, Line: line, Column: column, Text: text, Err: ErrNoMatch})
+				return
+			}
+
+			token := Token{Type: l.rules[rule].Name, Text: input[offset:end], Offset: offset, Line: line, Column: column}
+			for _, r := range token.Text {
+				if r == '\n' {
+					line, column = line+1, 1
+				} else {
+					column++
+				}
+			}
+			offset = end
+
+			if !l.rules[rule].Skip && !yield(token, nil) {
+				return
+			}
+		}
+	}
+}
+
+// longestMatch runs the product machine from the offset, returning the end of the longest match and its rule
+func (l *Lexer) longestMatch(input string, offset int) (int, int, bool) {
+	end, rule, found := 0, 0, false
+	state := l.machine.InitialState()
+	for i := offset; i < len(input); {
+		r, size := utf8.DecodeRuneInString(input[i:])
+		if r == utf8.RuneError && size <= 1 {
+			break
+		}
+		next, ok := l.machine.Next(state, r)
+		if !ok || next == deadState {
+			break
+		}
+
+		state = next
+		i += size
+		if accepted, ok := l.accepts[state]; ok {
+			end, rule, found = i, accepted, true
+		}
+	}
+	return end, rule, found
+}
+
+// The product state for when no rule can match any more
+const deadState = "dead"
+
+// build constructs the product machine by breadth first search over tuples of rule states.
+// A rule that has failed, or can no longer reach a final state, is "" in the tuple.
+func (l *Lexer) build() error {
+	dead := make([]map[string]struct{}, len(l.rules))
+	configs := make([]*fsm.Config, len(l.rules))
+	for i, rule := range l.rules {
+		dead[i] = make(map[string]struct{})
+		for _, state := range rule.Config.Analyze().DeadStates {
+			dead[i][state] = struct{}{}
+		}
+		configs[i] = rule.Config
+	}
+	classes := fsm.CommonClasses(configs...)
+
+	step := func(i int, state string, input rune) string {
+		if state == "" {
+			return ""
+		}
+		next, ok := l.rules[i].Config.Next(state, input)
+		if _, isDead := dead[i][next]; !ok || isDead {
+			return ""
+		}
+		return next
+	}
+
+	names := make(map[string]string)
+	var tuples [][]string
+	visit := func(tuple []string) string {
+		key := strings.Join(tuple, "\x00")
+		if strings.Trim(key, "\x00") == "" {
+			return deadState
+		}
+		if name, ok := names[key]; ok {
+			return name
+		}
+		name := fmt.Sprintf("q%d", len(tuples))
+		names[key] = name
+		tuples = append(tuples, tuple)
+		return name
+	}
+
+	initial := make([]string, len(l.rules))
+	for i, rule := range l.rules {
+		if _, isDead := dead[i][rule.Config.InitialState()]; !isDead {
+			initial[i] = rule.Config.InitialState()
+		}
+	}
+	initialState := visit(initial)
+	if initialState == deadState {
+		return fmt.Errorf("%w: every rule accepts nothing", ErrNoMatch)
+	}
+
+	l.accepts = make(map[string]int)
+	var transitions []fsm.RangeTransition
+	for current := 0; current < len(tuples); current++ {
+		tuple := tuples[current]
+		name := fmt.Sprintf("q%d", current)
+
+		// the final rule with the highest priority wins, and the first one listed between equals
+		for i, state := range tuple {
+			if state == "" || !l.rules[i].Config.IsFinal(state) {
+				continue
+			}
+			if best, ok := l.accepts[name]; !ok || l.rules[i].Priority > l.rules[best].Priority {
+				l.accepts[name] = i
+			}
+		}
+
+		targets := make(map[string][]fsm.RuneRange)
+		var order []string
+		for _, class := range classes {
+			next := make([]string, len(tuple))
+			for i, state := range tuple {
+				next[i] = step(i, state, class.Lo)
+			}
+			target := visit(next)
+			if _, ok := targets[target]; !ok {
+				order = append(order, target)
+			}
+			targets[target] = append(targets[target], class)
+		}
+		for _, target := range order {
+			transitions = append(transitions, fsm.RangeTransition{State: name, Inputs: targets[target], ResultState: target})
+		}
+	}
+	transitions = append(transitions, fsm.RangeTransition{State: deadState, Inputs: classes, ResultState: deadState})
+
+	states := []string{deadState}
+	var finalStates []string
+	for i := range tuples {
+		name := fmt.Sprintf("q%d", i)
+		states = append(states, name)
+		if _, ok := l.accepts[name]; ok {
+			finalStates = append(finalStates, name)
+		}
+	}
+	if len(finalStates) == 0 {
+		return fmt.Errorf("%w: every rule accepts nothing", ErrNoMatch)
+	}
+
+	machine, err := fsm.NewRangeConfig(states, classes, initialState, finalStates, transitions)
+	if err != nil {
+		return err
+	}
+	l.machine = machine
+	return nil
+}
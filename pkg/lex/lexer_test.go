@@ -0,0 +1,247 @@
+package lex
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Manuel9550/FiniteStateMachine/pkg/fsm"
+)
+
+// newWordConfig accepts exactly the word
+func newWordConfig(tb testing.TB, word string) *fsm.Config {
+	tb.Helper()
+
+	builder := fsm.NewBuilder().Initial("w0").Otherwise("none", "none")
+	runes := []rune(word)
+	for i, r := range runes {
+		state := "w" + string(rune('0'+i))
+		next := "w" + string(rune('0'+i+1))
+		builder.On(state, string(r), next).Otherwise(state, "none")
+	}
+	last := "w" + string(rune('0'+len(runes)))
+	conf, err := builder.Final(last).Otherwise(last, "none").Build()
+	if err != nil {
+		tb.Fatalf("word config should not have resulted in an error: %s", err)
+	}
+	return conf
+}
+
+// newRepeatConfig accepts one or more runes from first, followed by any runes from rest
+func newRepeatConfig(tb testing.TB, first []fsm.RuneRange, rest []fsm.RuneRange) *fsm.Config {
+	tb.Helper()
+
+	conf, err := fsm.NewBuilder().
+		Initial("start").
+		Final("more").
+		OnRange("start", first, "more").
+		Otherwise("start", "none").
+		OnRange("more", rest, "more").
+		Otherwise("more", "none").
+		Otherwise("none", "none").
+		Build()
+	if err != nil {
+		tb.Fatalf("repeat config should not have resulted in an error: %s", err)
+	}
+	return conf
+}
+
+func newTestLexer(tb testing.TB) *Lexer {
+	tb.Helper()
+
+	letters := []fsm.RuneRange{{Lo: 'a', Hi: 'z'}}
+	digits := []fsm.RuneRange{{Lo: '0', Hi: '9'}}
+	space := []fsm.RuneRange{fsm.Single(' '), fsm.Single('\t'), fsm.Single('\n')}
+
+	lexer, err := New([]Rule{
+		{Name: "ident", Config: newRepeatConfig(tb, letters, append(letters, digits...))},
+		{Name: "if", Config: newWordConfig(tb, "if"), Priority: 1},
+		{Name: "else", Config: newWordConfig(tb, "else"), Priority: 1},
+		{Name: "number", Config: newRepeatConfig(tb, digits, digits)},
+		{Name: "less", Config: newWordConfig(tb, "<")},
+		{Name: "lessEqual", Config: newWordConfig(tb, "<=")},
+		{Name: "space", Config: newRepeatConfig(tb, space, space), Skip: true},
+	})
+	if err != nil {
+		tb.Fatalf("lexer should not have resulted in an error: %s", err)
+	}
+	return lexer
+}
+
+func TestLexerTokenize(t *testing.T) {
+	lexer := newTestLexer(t)
+
+	type test struct {
+		name     string
+		input    string
+		expected []Token
+	}
+
+	tests := []test{
+		{
+			name:  "keywords beat identifiers of the same length",
+			input: "if iff else",
+			expected: []Token{
+				{Type: "if", Text: "if", Offset: 0, Line: 1, Column: 1},
+				{Type: "ident", Text: "iff", Offset: 3, Line: 1, Column: 4},
+				{Type: "else", Text: "else", Offset: 7, Line: 1, Column: 8},
+			},
+		},
+		{
+			name:  "longest match",
+			input: "x1<=42<y",
+			expected: []Token{
+				{Type: "ident", Text: "x1", Offset: 0, Line: 1, Column: 1},
+				{Type: "lessEqual", Text: "<=", Offset: 2, Line: 1, Column: 3},
+				{Type: "number", Text: "42", Offset: 4, Line: 1, Column: 5},
+				{Type: "less", Text: "<", Offset: 6, Line: 1, Column: 7},
+				{Type: "ident", Text: "y", Offset: 7, Line: 1, Column: 8},
+			},
+		},
+		{
+			name:  "lines and columns",
+			input: "if\n  x\n\ty",
+			expected: []Token{
+				{Type: "if", Text: "if", Offset: 0, Line: 1, Column: 1},
+				{Type: "ident", Text: "x", Offset: 5, Line: 2, Column: 3},
+				{Type: "ident", Text: "y", Offset: 8, Line: 3, Column: 2},
+			},
+		},
+		{
+			name:  "only skipped",
+			input: " \n ",
+		},
+	}
+
+	for _, currentTest := range tests {
+		t.Run(currentTest.name, func(t *testing.T) {
+			tokens, err := lexer.Tokenize(currentTest.input)
+			assert.Nil(t, err)
+			assert.Equal(t, currentTest.expected, tokens)
+		})
+	}
+}
+
+func TestLexerErrors(t *testing.T) {
+	lexer := newTestLexer(t)
+
+	type test struct {
+		name           string
+		input          string
+		expectedTokens int
+		expectedError  *Error
+	}
+
+	tests := []test{
+		{
+			name:           "unknown character",
+			input:          "x <= $y",
+			expectedTokens: 2,
+			expectedError:  &Error{Offset: 5, Line: 1, Column: 6, Text: "$", Err: ErrNoMatch},
+		},
+		{
+			name:           "non-ASCII after a newline",
+			input:          "x\nyé",
+			expectedTokens: 2,
+			expectedError:  &Error{Offset: 3, Line: 2, Column: 2, Text: "é", Err: ErrNoMatch},
+		},
+		{
+			name:           "invalid UTF-8",
+			input:          "x\xff",
+			expectedTokens: 1,
+			expectedError:  &Error{Offset: 1, Line: 1, Column: 2, Text: "\xff", Err: ErrNoMatch},
+		},
+	}
+
+	for _, currentTest := range tests {
+		t.Run(currentTest.name, func(t *testing.T) {
+			tokens, err := lexer.Tokenize(currentTest.input)
+			assert.Len(t, tokens, currentTest.expectedTokens)
+			assert.ErrorIs(t, err, ErrNoMatch)
+
+			var lexError *Error
+			assert.True(t, errors.As(err, &lexError))
+			assert.Equal(t, currentTest.expectedError, lexError)
+		})
+	}
+
+	_, err := lexer.Tokenize("if $")
+	assert.EqualError(t, err, `line 1 column 4 (byte 3): no rule matches the input: "$"`)
+}
+
+func TestLexerTokensStopsEarly(t *testing.T) {
+	lexer := newTestLexer(t)
+
+	var types []string
+	for token, err := range lexer.Tokens("if x else $") {
+		assert.Nil(t, err)
+		types = append(types, token.Type)
+		if len(types) == 2 {
+			break
+		}
+	}
+	assert.Equal(t, []string{"if", "ident"}, types)
+}
+
+func TestLexerMachine(t *testing.T) {
+	lexer := newTestLexer(t)
+	machine := lexer.Machine()
+
+	state := machine.InitialState()
+	for _, r := range "if" {
+		next, ok := machine.Next(state, r)
+		assert.True(t, ok)
+		state = next
+	}
+	rule, ok := lexer.RuleOf(state)
+	assert.True(t, ok)
+	assert.Equal(t, "if", rule)
+
+	_, ok = lexer.RuleOf(machine.InitialState())
+	assert.False(t, ok)
+}
+
+func TestNewLexerValidation(t *testing.T) {
+	word := newWordConfig(t, "a")
+	bytes, err := fsm.NewByteConfig([]string{"s"}, []byte{'a'}, "s", []string{"s"}, []fsm.ByteTransition{{State: "s", Input: 'a', ResultState: "s"}})
+	assert.Nil(t, err)
+
+	type test struct {
+		name          string
+		rules         []Rule
+		expectedError []error
+	}
+
+	tests := []test{
+		{name: "no rules", expectedError: []error{ErrNoRules}},
+		{
+			name: "every problem",
+			rules: []Rule{
+				{Name: "", Config: word},
+				{Name: "a", Config: nil},
+				{Name: "b", Config: bytes},
+				{Name: "b", Config: word},
+			},
+			expectedError: []error{ErrEmptyRuleName, ErrNilConfig, ErrByteMode, ErrDuplicateRule},
+		},
+		{name: "valid", rules: []Rule{{Name: "a", Config: word}}},
+	}
+
+	for _, currentTest := range tests {
+		t.Run(currentTest.name, func(t *testing.T) {
+			_, err := New(currentTest.rules)
+			if currentTest.expectedError == nil {
+				assert.Nil(t, err)
+				return
+			}
+			var validationError *fsm.ValidationError
+			if assert.True(t, errors.As(err, &validationError)) {
+				assert.Equal(t, len(currentTest.expectedError), len(validationError.Problems))
+			}
+			for _, expected := range currentTest.expectedError {
+				assert.ErrorIs(t, err, expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,190 @@
+package scxml
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/Manuel9550/FiniteStateMachine/pkg/fsm"
+)
+
+type exportedDocument struct {
+	XMLName   xml.Name        `xml:"scxml"`
+	Namespace string          `xml:"xmlns,attr"`
+	Version   string          `xml:"version,attr"`
+	Initial   string          `xml:"initial,attr"`
+	States    []exportedState `xml:",any"`
+}
+
+// exportedState is a <state>, or a <final> when its XMLName says so
+type exportedState struct {
+	XMLName     xml.Name
+	ID          string               `xml:"id,attr"`
+	Transitions []exportedTransition `xml:"transition"`
+}
+
+type exportedTransition struct {
+	Event  string `xml:"event,attr"`
+	Target string `xml:"target,attr"`
+}
+
+// Export writes the config as an SCXML document that Import reads back into the same config, given the same events,
+// as long as events has no names for symbols outside the config's alphabet.
+//
+// Each symbol is written as the first of its names in events, in sorted order, or with nil events, as the symbol
+// itself. Transitions that go back to the same state are left out, as SCXML stays put on events with no transition,
+// and the rest are grouped by target. Final states are written as <final>, which can't have transitions, so a config
+// whose final states can be left can't be exported. Neither can configs with rune ranges or in fsm.ByteMode, or with
+// states whose names aren't valid XML IDs, such as names with spaces, as SCXML names states with IDs.
+func Export(w io.Writer, config *fsm.Config, events Events) error {
+	if config == nil {
+		return fsm.ErrNilConfig
+	}
+	if config.Mode() != fsm.RuneMode {
+		return fmt.Errorf("%w: config reads bytes, not runes", ErrUnsupported)
+	}
+	alphabet := config.Alphabet()
+	var size int64
+	for _, class := range config.AlphabetRanges() {
+		size += class.Size()
+	}
+	if size != int64(len(alphabet)) {
+		return fmt.Errorf("%w: config has rune ranges in its alphabet", ErrUnsupported)
+	}
+
+	names, err := eventNames(alphabet, events)
+	if err != nil {
+		return err
+	}
+
+	var problems []error
+	document := exportedDocument{Namespace: Namespace, Version: "1.0", Initial: config.InitialState()}
+	for _, state := range config.States() {
+		if !isXMLID(state) {
+			problems = append(problems, fmt.Errorf("%w: state %q is not a valid XML ID", ErrUnsupported, state))
+			continue
+		}
+		exported := exportedState{XMLName: xml.Name{Local: "state"}, ID: state}
+		if config.IsFinal(state) {
+			exported.XMLName.Local = "final"
+		}
+
+		var targets []string
+		grouped := make(map[string][]string)
+		for _, symbol := range alphabet {
+			target, _ := config.Next(state, symbol)
+			if target == state {
+				continue
+			}
+			if _, ok := grouped[target]; !ok {
+				targets = append(targets, target)
+			}
+			grouped[target] = append(grouped[target], names[symbol])
+		}
+		if len(targets) > 0 && config.IsFinal(state) {
+			problems = append(problems, fmt.Errorf("%w: final state %s has transitions to other states", ErrUnsupported, state))
+			continue
+		}
+		for _, target := range targets {
+			exported.Transitions = append(exported.Transitions, exportedTransition{Event: strings.Join(grouped[target], " "), Target: target})
+		}
+		document.States = append(document.States, exported)
+	}
+	if len(problems) > 0 {
+		return errors.Join(problems...)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(document); err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, "\n")
+	return err
+}
+
+// eventNames returns the event name to write for each symbol.
+// Names must be usable in the event attribute of a transition, with only characters XML can hold, and must not match
+// each other as SCXML event descriptors, as "error" does "error.network", or the document would mean something else.
+func eventNames(alphabet []rune, events Events) (map[rune]string, error) {
+	var problems []error
+	names := make(map[rune]string, len(alphabet))
+	for _, symbol := range alphabet {
+		var candidates []string
+		if events == nil {
+			candidates = []string{string(symbol)}
+		}
+		for name, named := range events {
+			if named == symbol {
+				candidates = append(candidates, name)
+			}
+		}
+		slices.Sort(candidates)
+
+		switch {
+		case len(candidates) == 0:
+			problems = append(problems, fmt.Errorf("%w: %q is not in events", ErrInvalidEvent, symbol))
+		case candidates[0] == "" || strings.ContainsFunc(candidates[0], isSpace) || strings.Contains(candidates[0], "*") || strings.ContainsFunc(candidates[0], notXMLChar):
+			problems = append(problems, fmt.Errorf("%w: %q can't be written as event %q", ErrInvalidEvent, symbol, candidates[0]))
+		default:
+			names[symbol] = candidates[0]
+		}
+	}
+
+	for _, first := range alphabet {
+		for _, second := range alphabet {
+			if first != second && names[first] != "" && strings.HasPrefix(names[second], names[first]+".") {
+				problems = append(problems, fmt.Errorf("%w: event %s would also match event %s", ErrInvalidEvent, names[first], names[second]))
+			}
+		}
+	}
+	return names, errors.Join(problems...)
+}
+
+// notXMLChar reports whether the rune is outside the Char production of XML 1.0, so no document can hold it,
+// escaped or not
+func notXMLChar(r rune) bool {
+	switch {
+	case r == '\t' || r == '\n' || r == '\r':
+		return false
+	case r >= 0x20 && r <= 0xD7FF, r >= 0xE000 && r <= 0xFFFD, r >= 0x10000 && r <= utf8.MaxRune:
+		return false
+	}
+	return true
+}
+
+// isXMLID reports whether the name is an NCName, the form of XML IDs: a letter or underscore, followed by letters,
+// digits, underscores, hyphens, dots and combining characters
+func isXMLID(name string) bool {
+	for i, r := range name {
+		if !isNameStartChar(r) && (i == 0 || !isNameChar(r)) {
+			return false
+		}
+	}
+	return name != ""
+}
+
+// isNameStartChar is the NameStartChar production of XML 1.0, without the colon NCNames leave out
+func isNameStartChar(r rune) bool {
+	switch {
+	case r >= 'A' && r <= 'Z', r == '_', r >= 'a' && r <= 'z':
+		return true
+	case r >= 0xC0 && r <= 0xD6, r >= 0xD8 && r <= 0xF6, r >= 0xF8 && r <= 0x2FF, r >= 0x370 && r <= 0x37D,
+		r >= 0x37F && r <= 0x1FFF, r >= 0x200C && r <= 0x200D, r >= 0x2070 && r <= 0x218F, r >= 0x2C00 && r <= 0x2FEF,
+		r >= 0x3001 && r <= 0xD7FF, r >= 0xF900 && r <= 0xFDCF, r >= 0xFDF0 && r <= 0xFFFD, r >= 0x10000 && r <= 0xEFFFF:
+		return true
+	}
+	return false
+}
+
+// isNameChar is the NameChar production of XML 1.0, less the NameStartChars
+func isNameChar(r rune) bool {
+	return r == '-' || r == '.' || r >= '0' && r <= '9' || r == 0xB7 || r >= 0x300 && r <= 0x36F || r >= 0x203F && r <= 0x2040
+}
@@ -0,0 +1,141 @@
+package scxml
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Manuel9550/FiniteStateMachine/pkg/fsm"
+)
+
+func TestExport(t *testing.T) {
+	conf, err := Import(strings.NewReader(sessionDocument), sessionEvents)
+	assert.Nil(t, err)
+
+	var buffer bytes.Buffer
+	assert.Nil(t, Export(&buffer, conf, sessionEvents))
+	assert.Equal(t, `<?xml version="1.0" encoding="UTF-8"?>
+<scxml xmlns="http://www.w3.org/2005/07/scxml" version="1.0" initial="anonymous">
+  <state id="anonymous">
+    <transition event="error.auth" target="locked"></transition>
+    <transition event="login" target="authenticated"></transition>
+  </state>
+  <state id="authenticated">
+    <transition event="error.auth" target="anonymous"></transition>
+    <transition event="error.network logout" target="closed"></transition>
+  </state>
+  <final id="closed"></final>
+  <final id="locked"></final>
+</scxml>
+`, buffer.String())
+
+	reimported, err := Import(&buffer, sessionEvents)
+	assert.Nil(t, err)
+	assert.Equal(t, conf.Fingerprint(), reimported.Fingerprint())
+}
+
+func TestExportSingleRuneEvents(t *testing.T) {
+	conf, err := fsm.NewBuilder().
+		Initial("even").
+		Final("done").
+		On("even", "1", "odd").
+		On("odd", "1", "even").
+		On("even", "0", "even").
+		On("odd", "0", "done").
+		On("done", "01", "done").
+		Build()
+	assert.Nil(t, err)
+
+	var buffer bytes.Buffer
+	assert.Nil(t, Export(&buffer, conf, nil))
+	assert.Contains(t, buffer.String(), `<transition event="0" target="done"></transition>`)
+
+	reimported, err := Import(&buffer, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, conf.Fingerprint(), reimported.Fingerprint())
+}
+
+func TestExportErrors(t *testing.T) {
+	leavesFinal, err := fsm.NewConfig([]string{"a", "b"}, []rune{'x'}, "a", []string{"a"}, []fsm.Transition{
+		{State: "a", Input: 'x', ResultState: "b"},
+		{State: "b", Input: 'x', ResultState: "a"},
+	})
+	assert.Nil(t, err)
+	spaced, err := fsm.NewConfig([]string{"a"}, []rune{' '}, "a", []string{"a"}, []fsm.Transition{{State: "a", Input: ' ', ResultState: "a"}})
+	assert.Nil(t, err)
+	ranged, err := fsm.NewRangeConfig([]string{"a"}, []fsm.RuneRange{{Lo: 'a', Hi: 'z'}}, "a", []string{"a"}, []fsm.RangeTransition{{State: "a", Inputs: []fsm.RuneRange{{Lo: 'a', Hi: 'z'}}, ResultState: "a"}})
+	assert.Nil(t, err)
+	control, err := fsm.NewBuilder().Initial("a").Final("b").On("a", "\x01", "b").On("b", "\x01", "b").Build()
+	assert.Nil(t, err)
+	controlState, err := fsm.NewBuilder().Initial("a").Final("b\x02").On("a", "x", "b\x02").On("b\x02", "x", "b\x02").Build()
+	assert.Nil(t, err)
+	spacedState, err := fsm.NewBuilder().Initial("a b").Final("c").On("a b", "x", "c").On("c", "x", "c").Build()
+	assert.Nil(t, err)
+	numberedState, err := fsm.NewBuilder().Initial("a").Final("1").On("a", "x", "1").On("1", "x", "1").Build()
+	assert.Nil(t, err)
+	twoEvents, err := fsm.NewBuilder().Initial("a").Final("b").On("a", "xy", "b").On("b", "xy", "b").Build()
+	assert.Nil(t, err)
+
+	type test struct {
+		name          string
+		config        *fsm.Config
+		events        Events
+		expectedError error
+		expectedText  string
+	}
+
+	tests := []test{
+		{name: "nil config", expectedError: fsm.ErrNilConfig},
+		{name: "final state with transitions", config: leavesFinal, expectedError: ErrUnsupported, expectedText: "final state a has transitions to other states"},
+		{name: "rune ranges", config: ranged, expectedError: ErrUnsupported, expectedText: "rune ranges"},
+		{name: "symbol missing from events", config: leavesFinal, events: Events{"y": 'y'}, expectedError: ErrInvalidEvent, expectedText: `'x' is not in events`},
+		{name: "symbol that can't be an event name", config: spaced, expectedError: ErrInvalidEvent, expectedText: `' ' can't be written as event " "`},
+		{name: "symbol XML can't hold", config: control, expectedError: ErrInvalidEvent, expectedText: `'\x01' can't be written as event "\x01"`},
+		{name: "event name XML can't hold", config: control, events: Events{"\x01": '\x01'}, expectedError: ErrInvalidEvent},
+		{name: "state XML can't hold", config: controlState, expectedError: ErrUnsupported, expectedText: `state "b\x02" is not a valid XML ID`},
+		{name: "state with a space", config: spacedState, expectedError: ErrUnsupported, expectedText: `state "a b" is not a valid XML ID`},
+		{name: "state starting with a digit", config: numberedState, expectedError: ErrUnsupported, expectedText: `state "1" is not a valid XML ID`},
+		{name: "event names that match each other", config: twoEvents, events: Events{"go": 'x', "go.now": 'y'}, expectedError: ErrInvalidEvent, expectedText: "event go would also match event go.now"},
+	}
+
+	for _, currentTest := range tests {
+		t.Run(currentTest.name, func(t *testing.T) {
+			err := Export(&bytes.Buffer{}, currentTest.config, currentTest.events)
+			assert.ErrorIs(t, err, currentTest.expectedError)
+			if err != nil {
+				assert.Contains(t, err.Error(), currentTest.expectedText)
+			}
+		})
+	}
+}
+
+func TestExportControlCharacterRoundTrip(t *testing.T) {
+	conf, err := fsm.NewBuilder().Initial("a").Final("b").On("a", "\x01", "b").On("b", "\x01", "b").Build()
+	assert.Nil(t, err)
+
+	// the symbol can't be written as itself, but can be under another name
+	events := Events{"start": '\x01'}
+	var buffer bytes.Buffer
+	assert.Nil(t, Export(&buffer, conf, events))
+	reimported, err := Import(&buffer, events)
+	assert.Nil(t, err)
+	assert.Equal(t, conf.Fingerprint(), reimported.Fingerprint())
+}
+
+func TestExportStateNameRoundTrip(t *testing.T) {
+	conf, err := fsm.NewBuilder().
+		Initial("_état-1.débutʼ").
+		Final("fin·2").
+		On("_état-1.débutʼ", "x", "fin·2").
+		On("fin·2", "x", "fin·2").
+		Build()
+	assert.Nil(t, err)
+
+	var buffer bytes.Buffer
+	assert.Nil(t, Export(&buffer, conf, nil))
+	reimported, err := Import(&buffer, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, conf.Fingerprint(), reimported.Fingerprint())
+}
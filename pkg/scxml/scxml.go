@@ -0,0 +1,258 @@
+// Package scxml converts between fsm.Configs and SCXML, the W3C statechart format, for the flat subset of it that
+// a Config can describe: top level states and final states, the initial state, and transitions on events.
+// Anything else, such as nested states, conditions or executable content, is reported as unsupported.
+package scxml
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/Manuel9550/FiniteStateMachine/pkg/fsm"
+)
+
+// Namespace is the XML namespace of SCXML documents
+const Namespace = "http://www.w3.org/2005/07/scxml"
+
+var (
+	ErrInvalidDocument = errors.New("invalid SCXML document")
+	ErrUnsupported     = errors.New("SCXML construct is not supported")
+	ErrUnknownEvent    = errors.New("event has no symbol")
+	ErrInvalidEvent    = errors.New("symbol has no usable event name")
+)
+
+// Events maps SCXML event names to the alphabet symbols of a config.
+// A nil Events uses each event name of a single rune as its own symbol.
+type Events map[string]rune
+
+// singleRune returns the symbol for an event name when there are no Events: the name's only rune
+func singleRune(name string) (rune, bool) {
+	symbol, size := utf8.DecodeRuneInString(name)
+	return symbol, size > 0 && size == len(name) && symbol != utf8.RuneError
+}
+
+// element is any XML element, kept whole so that anything unsupported can be found and reported
+type element struct {
+	XMLName    xml.Name
+	Attributes []xml.Attr `xml:",any,attr"`
+	Children   []element  `xml:",any"`
+}
+
+func (e element) attribute(name string) (string, bool) {
+	for _, attribute := range e.Attributes {
+		if attribute.Name.Local == name && (attribute.Name.Space == "" || attribute.Name.Space == Namespace) {
+			return attribute.Value, true
+		}
+	}
+	return "", false
+}
+
+type transition struct {
+	events []string
+	// target is empty for a targetless transition, which consumes the event without leaving the state
+	target string
+}
+
+// Import reads an SCXML document into a config.
+//
+// Event names are mapped to symbols with events, and the alphabet of the config is every symbol in events,
+// or with nil events, every event the document uses. Transitions match events the way SCXML does: "error" also
+// matches "error.network", "error.*" means the same, and "*" matches everything, and for each state and event
+// the first matching transition in document order wins. Events with no matching transition, and events in a
+// final state, leave the state as it is.
+//
+// If the document uses anything outside the flat subset, an error wrapping ErrUnsupported says what and where.
+// Other problems with the document wrap ErrInvalidDocument or ErrUnknownEvent, and every one found is returned
+// together, joined with errors.Join.
+func Import(r io.Reader, events Events) (*fsm.Config, error) {
+	var root element
+	if err := xml.NewDecoder(r).Decode(&root); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidDocument, err)
+	}
+	if root.XMLName.Local != "scxml" {
+		return nil, fmt.Errorf("%w: root element is %s, not scxml", ErrInvalidDocument, root.XMLName.Local)
+	}
+
+	var problems []error
+	var states, finalStates []string
+	transitions := make(map[string][]transition)
+	seen := make(map[string]struct{})
+	for _, child := range root.Children {
+		kind := child.XMLName.Local
+		if kind != "state" && kind != "final" {
+			problems = append(problems, fmt.Errorf("%w: <%s>", ErrUnsupported, kind))
+			continue
+		}
+
+		id, _ := child.attribute("id")
+		if id == "" {
+			problems = append(problems, fmt.Errorf("%w: <%s> has no id", ErrInvalidDocument, kind))
+			continue
+		}
+		if _, ok := seen[id]; ok {
+			problems = append(problems, fmt.Errorf("%w: state %s is defined more than once", ErrInvalidDocument, id))
+			continue
+		}
+		seen[id] = struct{}{}
+		states = append(states, id)
+		if kind == "final" {
+			finalStates = append(finalStates, id)
+		}
+		if _, ok := child.attribute("initial"); ok {
+			problems = append(problems, fmt.Errorf("%w: state %s has an initial attribute, but nested states are not supported", ErrUnsupported, id))
+		}
+
+		for _, grandchild := range child.Children {
+			if grandchild.XMLName.Local != "transition" || kind == "final" {
+				problems = append(problems, fmt.Errorf("%w: <%s> in %s %s", ErrUnsupported, grandchild.XMLName.Local, kind, id))
+				continue
+			}
+			parsed, err := parseTransition(id, grandchild)
+			if err != nil {
+				problems = append(problems, err)
+				continue
+			}
+			transitions[id] = append(transitions[id], parsed)
+		}
+	}
+
+	initial, ok := root.attribute("initial")
+	switch {
+	case strings.ContainsFunc(initial, isSpace):
+		problems = append(problems, fmt.Errorf("%w: more than one initial state: %s", ErrUnsupported, initial))
+	case !ok && len(states) > 0:
+		// SCXML starts in the first state when no initial state is given
+		initial = states[0]
+	}
+
+	alphabet, err := alphabetOf(events, transitions, states)
+	if err != nil {
+		problems = append(problems, err)
+	}
+	if len(problems) > 0 {
+		return nil, errors.Join(problems...)
+	}
+
+	var configTransitions []fsm.Transition
+	for _, state := range states {
+		for _, symbol := range alphabet {
+			configTransitions = append(configTransitions, fsm.Transition{State: state, Input: symbol, ResultState: resultState(events, transitions[state], state, symbol)})
+		}
+	}
+	return fsm.NewConfig(states, alphabet, initial, finalStates, configTransitions)
+}
+
+func parseTransition(state string, e element) (transition, error) {
+	if len(e.Children) > 0 {
+		return transition{}, fmt.Errorf("%w: <%s> in a transition from %s", ErrUnsupported, e.Children[0].XMLName.Local, state)
+	}
+	if _, ok := e.attribute("cond"); ok {
+		return transition{}, fmt.Errorf("%w: transition from %s has a condition", ErrUnsupported, state)
+	}
+
+	event, _ := e.attribute("event")
+	events := strings.Fields(event)
+	if len(events) == 0 {
+		return transition{}, fmt.Errorf("%w: transition from %s has no event", ErrUnsupported, state)
+	}
+	target, _ := e.attribute("target")
+	if strings.ContainsFunc(target, isSpace) {
+		return transition{}, fmt.Errorf("%w: transition from %s has more than one target: %s", ErrUnsupported, state, target)
+	}
+	return transition{events: events, target: target}, nil
+}
+
+// alphabetOf returns the symbols of every event in events, or with nil events, of every event the transitions use.
+// Every event the transitions name must have a symbol.
+func alphabetOf(events Events, transitions map[string][]transition, states []string) ([]rune, error) {
+	var problems []error
+	var alphabet []rune
+	seen := make(map[rune]struct{})
+	add := func(symbol rune) {
+		if _, ok := seen[symbol]; !ok {
+			seen[symbol] = struct{}{}
+			alphabet = append(alphabet, symbol)
+		}
+	}
+
+	for name, symbol := range events {
+		if name != "" {
+			add(symbol)
+		}
+	}
+	for _, state := range states {
+		for _, current := range transitions[state] {
+			for _, descriptor := range current.events {
+				if descriptor == "*" {
+					continue
+				}
+				if events == nil {
+					if symbol, ok := singleRune(strings.TrimSuffix(descriptor, ".*")); ok {
+						add(symbol)
+						continue
+					}
+				} else if named(events, descriptor) {
+					continue
+				}
+				problems = append(problems, fmt.Errorf("%w: %s in a transition from %s", ErrUnknownEvent, descriptor, state))
+			}
+		}
+	}
+	return alphabet, errors.Join(problems...)
+}
+
+// resultState returns where the first transition matching the symbol goes, or the state itself if none match
+func resultState(events Events, transitions []transition, state string, symbol rune) string {
+	for _, current := range transitions {
+		for _, descriptor := range current.events {
+			if matches(events, descriptor, symbol) {
+				if current.target == "" {
+					return state
+				}
+				return current.target
+			}
+		}
+	}
+	return state
+}
+
+// matches reports whether the event descriptor of a transition matches an event named for the symbol
+func matches(events Events, descriptor string, symbol rune) bool {
+	if descriptor == "*" {
+		return true
+	}
+	descriptor = strings.TrimSuffix(descriptor, ".*")
+	if events == nil {
+		return descriptor == string(symbol)
+	}
+	for name, current := range events {
+		if current == symbol && matchesName(descriptor, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// named reports whether the event descriptor matches any event in events
+func named(events Events, descriptor string) bool {
+	descriptor = strings.TrimSuffix(descriptor, ".*")
+	for name := range events {
+		if matchesName(descriptor, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesName reports whether the event descriptor, without any trailing ".*", matches the event name:
+// either they are the same, or the descriptor is a prefix of the name made of whole dot separated tokens
+func matchesName(descriptor string, name string) bool {
+	return name == descriptor || strings.HasPrefix(name, descriptor+".")
+}
+
+func isSpace(r rune) bool {
+	return r == ' ' || r == '\t' || r == '\n' || r == '\r'
+}
@@ -0,0 +1,130 @@
+package scxml
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Manuel9550/FiniteStateMachine/pkg/fsm"
+)
+
+var sessionEvents = Events{"login": 'l', "logout": 'o', "error.network": 'n', "error.auth": 'a', "tick": 't'}
+
+const sessionDocument = `<?xml version="1.0" encoding="UTF-8"?>
+<scxml xmlns="http://www.w3.org/2005/07/scxml" version="1.0" initial="anonymous">
+  <state id="anonymous">
+    <transition event="login" target="authenticated"/>
+    <transition event="error.auth" target="locked"/>
+  </state>
+  <state id="authenticated">
+    <transition event="error.auth" target="anonymous"/>
+    <transition event="error" target="closed"/>
+    <transition event="logout" target="closed"/>
+    <transition event="login"/>
+  </state>
+  <final id="locked"/>
+  <final id="closed"/>
+</scxml>`
+
+func TestImport(t *testing.T) {
+	conf, err := Import(strings.NewReader(sessionDocument), sessionEvents)
+	assert.Nil(t, err)
+	assert.Equal(t, "anonymous", conf.InitialState())
+	assert.Equal(t, []string{"anonymous", "authenticated", "closed", "locked"}, conf.States())
+	assert.Equal(t, []string{"closed", "locked"}, conf.FinalStates())
+	assert.Equal(t, []rune{'a', 'l', 'n', 'o', 't'}, conf.Alphabet())
+
+	type test struct {
+		name     string
+		state    string
+		input    rune
+		expected string
+	}
+
+	tests := []test{
+		{name: "transition", state: "anonymous", input: 'l', expected: "authenticated"},
+		{name: "no transition stays put", state: "anonymous", input: 'o', expected: "anonymous"},
+		{name: "an event no transition names stays put", state: "authenticated", input: 't', expected: "authenticated"},
+		{name: "the first matching transition wins", state: "authenticated", input: 'a', expected: "anonymous"},
+		{name: "a prefix matches longer event names", state: "authenticated", input: 'n', expected: "closed"},
+		{name: "a targetless transition stays put", state: "authenticated", input: 'l', expected: "authenticated"},
+		{name: "final states stay put", state: "closed", input: 'l', expected: "closed"},
+	}
+
+	for _, currentTest := range tests {
+		t.Run(currentTest.name, func(t *testing.T) {
+			next, ok := conf.Next(currentTest.state, currentTest.input)
+			assert.True(t, ok)
+			assert.Equal(t, currentTest.expected, next)
+		})
+	}
+}
+
+func TestImportSingleRuneEvents(t *testing.T) {
+	document := `<scxml xmlns="http://www.w3.org/2005/07/scxml" version="1.0">
+  <state id="even"><transition event="1" target="odd"/></state>
+  <final id="odd"><!-- no way out --></final>
+  <state id="unused"><transition event="*" target="even"/><transition event="0.*" target="odd"/></state>
+</scxml>`
+
+	conf, err := Import(strings.NewReader(document), nil)
+	assert.Nil(t, err)
+	assert.Equal(t, "even", conf.InitialState())
+	assert.Equal(t, []rune{'0', '1'}, conf.Alphabet())
+
+	machine, err := fsm.New(*conf)
+	assert.Nil(t, err)
+	_, accepted := machine.Process("001")
+	assert.True(t, accepted)
+	_, accepted = machine.Process("00")
+	assert.False(t, accepted)
+	next, _ := conf.Next("unused", '0')
+	assert.Equal(t, "even", next)
+}
+
+func TestImportErrors(t *testing.T) {
+	type test struct {
+		name          string
+		document      string
+		expectedError error
+		expectedText  string
+	}
+
+	wrap := func(states string) string {
+		return `<scxml xmlns="http://www.w3.org/2005/07/scxml" version="1.0">` + states + `</scxml>`
+	}
+
+	tests := []test{
+		{name: "not XML", document: "<scxml", expectedError: ErrInvalidDocument},
+		{name: "not SCXML", document: "<html/>", expectedError: ErrInvalidDocument, expectedText: "root element is html"},
+		{name: "parallel", document: wrap(`<parallel id="p"/>`), expectedError: ErrUnsupported, expectedText: "<parallel>"},
+		{name: "nested states", document: wrap(`<state id="a"><state id="b"/></state>`), expectedError: ErrUnsupported, expectedText: "<state> in state a"},
+		{name: "history", document: wrap(`<state id="a"><history id="h"/></state>`), expectedError: ErrUnsupported, expectedText: "<history> in state a"},
+		{name: "executable content", document: wrap(`<state id="a"><onentry><log expr="'hi'"/></onentry></state>`), expectedError: ErrUnsupported, expectedText: "<onentry> in state a"},
+		{name: "actions", document: wrap(`<state id="a"><transition event="x" target="a"><raise event="y"/></transition></state>`), expectedError: ErrUnsupported, expectedText: "<raise> in a transition from a"},
+		{name: "condition", document: wrap(`<state id="a"><transition event="x" cond="false" target="a"/></state>`), expectedError: ErrUnsupported, expectedText: "has a condition"},
+		{name: "eventless transition", document: wrap(`<state id="a"><transition target="a"/></state>`), expectedError: ErrUnsupported, expectedText: "has no event"},
+		{name: "several targets", document: wrap(`<state id="a"><transition event="x" target="a b"/></state>`), expectedError: ErrUnsupported, expectedText: "more than one target"},
+		{name: "transition from a final state", document: wrap(`<final id="a"><transition event="x" target="a"/></final>`), expectedError: ErrUnsupported, expectedText: "<transition> in final a"},
+		{name: "missing id", document: wrap(`<state/>`), expectedError: ErrInvalidDocument, expectedText: "<state> has no id"},
+		{name: "duplicate id", document: wrap(`<state id="a"/><final id="a"/>`), expectedError: ErrInvalidDocument, expectedText: "state a is defined more than once"},
+		{name: "unknown event", document: wrap(`<state id="a"><transition event="xy" target="a"/></state>`), expectedError: ErrUnknownEvent, expectedText: "xy in a transition from a"},
+		{name: "unknown target", document: wrap(`<state id="a"><transition event="x" target="b"/></state><final id="c"/>`), expectedError: fsm.ErrInvalidResultState},
+	}
+
+	for _, currentTest := range tests {
+		t.Run(currentTest.name, func(t *testing.T) {
+			_, err := Import(strings.NewReader(currentTest.document), nil)
+			assert.ErrorIs(t, err, currentTest.expectedError)
+			if err != nil {
+				assert.Contains(t, err.Error(), currentTest.expectedText)
+			}
+		})
+	}
+
+	_, err := Import(strings.NewReader(wrap(`<state id="a"><transition event="logout.all" target="a"/></state>`)), sessionEvents)
+	assert.ErrorIs(t, err, ErrUnknownEvent)
+	_, err = Import(strings.NewReader(wrap(`<state id="a"><transition event="error" target="a"/></state>`)), sessionEvents)
+	assert.ErrorIs(t, err, fsm.ErrEmptyFinalStates)
+}
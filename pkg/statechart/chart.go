@@ -0,0 +1,309 @@
+// Package statechart describes machines with nested states, where a composite state contains a sub-machine of
+// its own, and flattens them into plain fsm.Configs for running and analysis.
+package statechart
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/Manuel9550/FiniteStateMachine/pkg/fsm"
+)
+
+var (
+	ErrEmptyStateName      = errors.New("state name cannot be empty")
+	ErrInvalidStateName    = errors.New("state name cannot contain any of " + reservedCharacters)
+	ErrDuplicateParent     = errors.New("state has more than one parent")
+	ErrHierarchyCycle      = errors.New("state is its own ancestor")
+	ErrNoInitialState      = errors.New("chart has no initial state")
+	ErrInvalidInitialChild = errors.New("initial child is not a child of the state")
+	ErrDuplicateTransition = errors.New("transition is defined more than once")
+	ErrNoEvents            = errors.New("transition has no events")
+	ErrInvalidHistory      = errors.New("history state can only be the target of transitions")
+)
+
+// Flattened state names join chart state names with these, so chart state names can't use them
+const reservedCharacters = ",[]="
+
+type historyKind int
+
+const (
+	noHistory historyKind = iota
+	shallowHistory
+	deepHistory
+)
+
+type state struct {
+	name   string
+	parent string
+	// children are in the order they were declared, and the first is the initial child unless set otherwise
+	children    []string
+	initial     string
+	transitions map[rune]string
+	final       bool
+	// parallel states are in all of their children at once, each child being an independent region
+	parallel bool
+	// history is set for history pseudo-states, which are in their parent but aren't among its children
+	history   historyKind
+	histories []string
+}
+
+// Chart is a statechart: a machine whose states can contain sub-machines.
+//
+// A composite state is one with children. Entering it enters its initial child, and so on down to a state with no
+// children, so the chart is in one such leaf state, and in each of its ancestors. A transition defined on a
+// composite state applies in all of its descendants, unless a descendant defines its own transition for the event.
+// Events that no active state has a transition for are ignored.
+//
+// A parallel state instead has orthogonal regions: it is in all of its children at once, and each of them advances
+// on the same events independently, so the chart can be in several leaf states at once, one per region.
+//
+// A history state remembers where a composite state was when it was last left, and a transition to it goes back
+// there instead of to the composite state's initial child.
+//
+// Like fsm.Builder, states are declared as they are first used, and problems are collected as the chart is
+// put together, and all returned together by Flatten.
+//
+//	chart := statechart.New().
+//		Child("active", "loading", "ready").
+//		Initial("active").
+//		Final("ready").
+//		On("loading", "r", "ready").
+//		On("active", "c", "idle")
+type Chart struct {
+	states   map[string]*state
+	order    []string
+	initial  string
+	events   []rune
+	problems []error
+}
+
+func New() *Chart {
+	return &Chart{states: make(map[string]*state)}
+}
+
+// State declares top level states. States also get declared by being used in any other method, so this is only
+// needed to fix the order states are declared in.
+func (c *Chart) State(states ...string) *Chart {
+	for _, name := range states {
+		c.declare(name)
+	}
+	return c
+}
+
+func (c *Chart) declare(name string) *state {
+	if existing, ok := c.states[name]; ok {
+		return existing
+	}
+
+	switch {
+	case name == "":
+		c.problems = append(c.problems, ErrEmptyStateName)
+	case strings.ContainsAny(name, reservedCharacters):
+		c.problems = append(c.problems, fmt.Errorf("%w: %s", ErrInvalidStateName, name))
+	}
+	declared := &state{name: name, transitions: make(map[rune]string)}
+	c.states[name] = declared
+	c.order = append(c.order, name)
+	return declared
+}
+
+// Child nests the children inside parent, making parent a composite state.
+// The first child is its initial child, unless InitialChild says otherwise.
+func (c *Chart) Child(parent string, children ...string) *Chart {
+	composite := c.declare(parent)
+	for _, name := range children {
+		child := c.declare(name)
+		if child.history != noHistory {
+			c.problems = append(c.problems, fmt.Errorf("%w: %s is a history state, not a child of %s", ErrInvalidHistory, name, parent))
+			continue
+		}
+		if child.parent == parent {
+			continue
+		}
+		if child.parent != "" {
+			c.problems = append(c.problems, fmt.Errorf("%w: %s is in both %s and %s", ErrDuplicateParent, name, child.parent, parent))
+			continue
+		}
+		child.parent = parent
+		composite.children = append(composite.children, name)
+	}
+	return c
+}
+
+// Parallel nests the regions inside parent, making parent a parallel state: entering it enters every region,
+// and each region handles events independently of the others.
+func (c *Chart) Parallel(parent string, regions ...string) *Chart {
+	c.declare(parent).parallel = true
+	return c.Child(parent, regions...)
+}
+
+// ShallowHistory declares a shallow history state in parent. Transitions to it enter the child parent was in when it
+// was last left, and that child's initial child, and so on. Until parent has been left, they enter parent as usual.
+func (c *Chart) ShallowHistory(parent string, name string) *Chart {
+	return c.addHistory(parent, name, shallowHistory)
+}
+
+// DeepHistory declares a deep history state in parent. Transitions to it enter every state parent was in when it
+// was last left, all the way down. Until parent has been left, they enter parent as usual.
+func (c *Chart) DeepHistory(parent string, name string) *Chart {
+	return c.addHistory(parent, name, deepHistory)
+}
+
+func (c *Chart) addHistory(parent string, name string, kind historyKind) *Chart {
+	composite := c.declare(parent)
+	history := c.declare(name)
+	if history.parent != "" && history.parent != parent {
+		c.problems = append(c.problems, fmt.Errorf("%w: %s is in both %s and %s", ErrDuplicateParent, name, history.parent, parent))
+		return c
+	}
+	if history.history != noHistory {
+		return c
+	}
+	if history.parent == parent {
+		c.problems = append(c.problems, fmt.Errorf("%w: %s is already a child of %s", ErrInvalidHistory, name, parent))
+		return c
+	}
+	history.parent = parent
+	history.history = kind
+	composite.histories = append(composite.histories, name)
+	return c
+}
+
+// InitialChild sets the child a composite state enters when it is entered
+func (c *Chart) InitialChild(parent string, child string) *Chart {
+	c.declare(parent).initial = child
+	return c
+}
+
+// Initial sets the state the chart starts in
+func (c *Chart) Initial(name string) *Chart {
+	if c.initial != "" && c.initial != name {
+		c.problems = append(c.problems, fmt.Errorf("%w: initial state set twice: %s and %s", fsm.ErrInvalidInitialState, c.initial, name))
+		return c
+	}
+	c.declare(name)
+	c.initial = name
+	return c
+}
+
+// Final marks states as final. A composite state being final makes all of its descendants final.
+func (c *Chart) Final(states ...string) *Chart {
+	for _, name := range states {
+		c.declare(name).final = true
+	}
+	return c
+}
+
+// On adds a transition from state to target for every character of events.
+// If state is a composite state, the transition applies in all of its descendants that don't override it.
+func (c *Chart) On(source string, events string, target string) *Chart {
+	from := c.declare(source)
+	c.declare(target)
+	if events == "" {
+		c.problems = append(c.problems, fmt.Errorf("%w: %s -> %s", ErrNoEvents, source, target))
+		return c
+	}
+
+	for _, event := range events {
+		if existing, ok := from.transitions[event]; ok {
+			if existing != target {
+				c.problems = append(c.problems, fmt.Errorf("%w: %s:%c goes to both %s and %s", ErrDuplicateTransition, source, event, existing, target))
+			}
+			continue
+		}
+		from.transitions[event] = target
+		c.addEvent(event)
+	}
+	return c
+}
+
+func (c *Chart) addEvent(event rune) {
+	for _, existing := range c.events {
+		if existing == event {
+			return
+		}
+	}
+	c.events = append(c.events, event)
+}
+
+// validate returns every problem with the chart, including those collected while it was put together
+func (c *Chart) validate() error {
+	problems := append([]error(nil), c.problems...)
+
+	if c.initial == "" {
+		problems = append(problems, ErrNoInitialState)
+	}
+
+	for _, name := range c.order {
+		current := c.states[name]
+		if current.initial != "" {
+			child, ok := c.states[current.initial]
+			switch {
+			case current.parallel:
+				problems = append(problems, fmt.Errorf("%w: parallel state %s enters all of its children", ErrInvalidInitialChild, name))
+			case !ok || child.parent != name:
+				problems = append(problems, fmt.Errorf("%w: %s is not in %s", ErrInvalidInitialChild, current.initial, name))
+			case child.history != noHistory:
+				problems = append(problems, fmt.Errorf("%w: %s is a history state", ErrInvalidInitialChild, current.initial))
+			}
+		}
+
+		if current.history != noHistory {
+			switch {
+			case len(current.children) > 0 || len(current.histories) > 0 || len(current.transitions) > 0 || current.final:
+				problems = append(problems, fmt.Errorf("%w: %s has children, transitions or is final", ErrInvalidHistory, name))
+			case len(c.states[current.parent].children) == 0:
+				problems = append(problems, fmt.Errorf("%w: %s is not in a composite state", ErrInvalidHistory, name))
+			}
+		}
+
+		seen := map[string]struct{}{name: {}}
+		for ancestor := current.parent; ancestor != ""; ancestor = c.states[ancestor].parent {
+			if _, ok := seen[ancestor]; ok {
+				problems = append(problems, fmt.Errorf("%w: %s", ErrHierarchyCycle, name))
+				break
+			}
+			seen[ancestor] = struct{}{}
+		}
+	}
+
+	if len(problems) == 0 {
+		return nil
+	}
+	return &fsm.ValidationError{Problems: problems}
+}
+
+// initialChild returns the child the composite state enters by default
+func (c *Chart) initialChild(name string) string {
+	current := c.states[name]
+	if current.initial != "" {
+		return current.initial
+	}
+	return current.children[0]
+}
+
+// isDescendant reports whether name is strictly inside ancestor. Everything is inside the root, "".
+func (c *Chart) isDescendant(name string, ancestor string) bool {
+	if name == "" {
+		return false
+	}
+	for parent := c.states[name].parent; ; parent = c.states[parent].parent {
+		if parent == ancestor {
+			return true
+		}
+		if parent == "" {
+			return false
+		}
+	}
+}
+
+// isFinal reports whether the state or any of its ancestors is marked final
+func (c *Chart) isFinal(name string) bool {
+	for ; name != ""; name = c.states[name].parent {
+		if c.states[name].final {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,260 @@
+package statechart
+
+import (
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/Manuel9550/FiniteStateMachine/pkg/fsm"
+)
+
+// active is the set of states a chart is in: a leaf state in each active region, and all of their ancestors
+type active map[string]struct{}
+
+// configuration is everything that decides what a chart does next: the states it is in,
+// and the states each history state recorded when its parent was last left
+type configuration struct {
+	states  active
+	history map[string][]string
+}
+
+// Flatten compiles the chart into a plain config with the same behavior, whose alphabet is the chart's events.
+// Each state of the config is a combination of leaf states the chart can be in at once, named by joining them with
+// commas in the order they were declared; without parallel states, that is a single leaf state with its own name.
+// This is the product of the regions of each parallel state, but only the combinations the chart can actually
+// get to from its initial state are included. Events ignored by every active state loop back.
+// A combination is final when every leaf state in it is final.
+// Once history states have recorded something, what they recorded is part of the state too, and is added to its
+// name, as in "idle[resume=running]", so the same leaf states can show up more than once with different histories.
+func (c *Chart) Flatten() (*fsm.Config, error) {
+	if err := c.validate(); err != nil {
+		return nil, err
+	}
+
+	events := slices.Clone(c.events)
+	slices.Sort(events)
+
+	initial := configuration{states: make(active)}
+	c.enter(initial, "", c.initial)
+	initialName := c.name(initial)
+
+	seen := map[string]struct{}{initialName: {}}
+	queue := []configuration{initial}
+	var states, finalStates []string
+	var transitions []fsm.Transition
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		name := c.name(current)
+		states = append(states, name)
+		if c.isFinalConfiguration(current) {
+			finalStates = append(finalStates, name)
+		}
+
+		for _, event := range events {
+			next := c.step(current, event)
+			nextName := c.name(next)
+			transitions = append(transitions, fsm.Transition{State: name, Input: event, ResultState: nextName})
+			if _, ok := seen[nextName]; !ok {
+				seen[nextName] = struct{}{}
+				queue = append(queue, next)
+			}
+		}
+	}
+
+	return fsm.NewConfig(states, events, initialName, finalStates, transitions)
+}
+
+type transition struct {
+	source string
+	target string
+	domain string
+}
+
+// step returns the states the chart is in after the event.
+// For each active leaf state, the innermost of it and its ancestors with a transition for the event takes it.
+// Transitions in separate regions are all taken. When two would leave the same states, the one from the innermost
+// source wins, and otherwise the one for the leaf state declared first. If there are none, nothing changes.
+func (c *Chart) step(current configuration, event rune) configuration {
+	var selected []transition
+	for _, leaf := range c.leaves(current.states) {
+		candidate, ok := c.handler(leaf, event)
+		if !ok {
+			continue
+		}
+
+		keep := true
+		for i, existing := range selected {
+			if !c.conflict(candidate, existing) {
+				continue
+			}
+			if c.isDescendant(candidate.source, existing.source) {
+				selected[i] = candidate
+			}
+			keep = false
+			break
+		}
+		if keep {
+			selected = append(selected, candidate)
+		}
+	}
+
+	next := current
+	for _, chosen := range selected {
+		next = c.take(next, chosen)
+	}
+	return next
+}
+
+// handler returns the transition for the event from the innermost of the leaf state and its ancestors that has one
+func (c *Chart) handler(leaf string, event rune) (transition, bool) {
+	for source := leaf; source != ""; source = c.states[source].parent {
+		if target, ok := c.states[source].transitions[event]; ok {
+			return transition{source: source, target: target, domain: c.domain(source, target)}, true
+		}
+	}
+	return transition{}, false
+}
+
+// conflict reports whether the transitions would leave some of the same states
+func (c *Chart) conflict(first transition, second transition) bool {
+	return first.domain == second.domain || c.isDescendant(first.domain, second.domain) || c.isDescendant(second.domain, first.domain)
+}
+
+// take leaves every active state inside the transition's domain, recording history as it goes, then enters the target
+func (c *Chart) take(current configuration, chosen transition) configuration {
+	next := configuration{states: make(active, len(current.states)), history: current.history}
+	copied := false
+	for _, name := range c.order {
+		if _, ok := current.states[name]; !ok {
+			continue
+		}
+		if !c.isDescendant(name, chosen.domain) {
+			next.states[name] = struct{}{}
+			continue
+		}
+
+		for _, history := range c.states[name].histories {
+			if !copied {
+				next.history = maps.Clone(current.history)
+				if next.history == nil {
+					next.history = make(map[string][]string)
+				}
+				copied = true
+			}
+			next.history[history] = c.record(current.states, name, c.states[history].history)
+		}
+	}
+	c.enter(next, chosen.domain, chosen.target)
+	return next
+}
+
+// record returns what a history state in parent remembers: the active children of parent for shallow history,
+// and the active leaf states inside it for deep history
+func (c *Chart) record(current active, parent string, kind historyKind) []string {
+	var recorded []string
+	for _, name := range c.order {
+		if _, ok := current[name]; !ok {
+			continue
+		}
+		if kind == shallowHistory && c.states[name].parent == parent ||
+			kind == deepHistory && len(c.states[name].children) == 0 && c.isDescendant(name, parent) {
+			recorded = append(recorded, name)
+		}
+	}
+	return recorded
+}
+
+// domain returns the innermost state that a transition from source to target stays inside: the closest ancestor of
+// source that contains target, or the root, "". The source itself is always left, even if the target is inside it.
+// A parallel state can't be the domain, as moving between its regions means leaving and re-entering all of them.
+func (c *Chart) domain(source string, target string) string {
+	for ancestor := c.states[source].parent; ancestor != ""; ancestor = c.states[ancestor].parent {
+		if !c.states[ancestor].parallel && c.isDescendant(target, ancestor) {
+			return ancestor
+		}
+	}
+	return ""
+}
+
+// enter adds the target, and its ancestors inside domain, to the active states, and then enters the target.
+// Any parallel ancestor also enters its other regions, except that a history state's parent is left to the history.
+func (c *Chart) enter(current configuration, domain string, target string) {
+	onPath := target
+	for ancestor := c.states[target].parent; ancestor != domain && ancestor != ""; ancestor = c.states[ancestor].parent {
+		current.states[ancestor] = struct{}{}
+		if c.states[ancestor].parallel && c.states[onPath].history == noHistory {
+			for _, region := range c.states[ancestor].children {
+				if _, ok := current.states[region]; !ok && region != onPath {
+					c.enterDefault(current.states, region)
+				}
+			}
+		}
+		onPath = ancestor
+	}
+
+	if c.states[target].history == noHistory {
+		c.enterDefault(current.states, target)
+		return
+	}
+	parent := c.states[target].parent
+	recorded, ok := current.history[target]
+	if !ok {
+		c.enterDefault(current.states, parent)
+		return
+	}
+	for _, name := range recorded {
+		if c.states[target].history == shallowHistory {
+			c.enterDefault(current.states, name)
+			continue
+		}
+		for ; name != parent; name = c.states[name].parent {
+			current.states[name] = struct{}{}
+		}
+	}
+}
+
+// enterDefault enters the state, and then its initial child (or every region, for a parallel state),
+// and so on down to the leaves
+func (c *Chart) enterDefault(current active, name string) {
+	current[name] = struct{}{}
+	switch entered := c.states[name]; {
+	case entered.parallel:
+		for _, region := range entered.children {
+			c.enterDefault(current, region)
+		}
+	case len(entered.children) > 0:
+		c.enterDefault(current, c.initialChild(name))
+	}
+}
+
+// leaves returns the active states with no children, in the order they were declared
+func (c *Chart) leaves(current active) []string {
+	var leaves []string
+	for _, name := range c.order {
+		if _, ok := current[name]; ok && len(c.states[name].children) == 0 {
+			leaves = append(leaves, name)
+		}
+	}
+	return leaves
+}
+
+// name returns the name of the flattened state for the configuration
+func (c *Chart) name(current configuration) string {
+	name := strings.Join(c.leaves(current.states), ",")
+	for _, history := range c.order {
+		if recorded, ok := current.history[history]; ok {
+			name += "[" + history + "=" + strings.Join(recorded, ",") + "]"
+		}
+	}
+	return name
+}
+
+func (c *Chart) isFinalConfiguration(current configuration) bool {
+	for _, leaf := range c.leaves(current.states) {
+		if !c.isFinal(leaf) {
+			return false
+		}
+	}
+	return true
+}
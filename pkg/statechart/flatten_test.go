@@ -0,0 +1,252 @@
+package statechart
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Manuel9550/FiniteStateMachine/pkg/fsm"
+)
+
+// newWorkflowChart is a job that is idle until started, then loads and runs until done.
+// Cancelling from anywhere in active goes back to idle, except once it is running, where it asks for confirmation.
+func newWorkflowChart() *Chart {
+	return New().
+		State("idle").
+		Child("active", "loading", "working").
+		Child("working", "running", "confirming").
+		State("done").
+		Initial("idle").
+		Final("done").
+		On("idle", "s", "active").
+		On("active", "c", "idle").
+		On("loading", "l", "working").
+		On("running", "c", "confirming").
+		On("confirming", "y", "idle").
+		On("confirming", "n", "running").
+		On("working", "f", "done").
+		On("working", "r", "working")
+}
+
+func flattenedMachine(t *testing.T, chart *Chart) *fsm.FiniteStateMachine {
+	t.Helper()
+
+	conf, err := chart.Flatten()
+	if err != nil {
+		t.Fatalf("flattening should not have resulted in an error: %s", err)
+	}
+	machine, err := fsm.New(*conf)
+	if err != nil {
+		t.Fatalf("flattened config should not have resulted in an error: %s", err)
+	}
+	return machine
+}
+
+func TestFlattenHierarchy(t *testing.T) {
+	machine := flattenedMachine(t, newWorkflowChart())
+	assert.Equal(t, "idle", machine.Config.InitialState())
+	assert.Equal(t, []string{"confirming", "done", "idle", "loading", "running"}, machine.Config.States())
+
+	type test struct {
+		name          string
+		input         string
+		expectedState string
+	}
+
+	tests := []test{
+		{name: "entering a parent enters its initial child", input: "s", expectedState: "loading"},
+		{name: "entering nested parents goes all the way down", input: "sl", expectedState: "running"},
+		{name: "a parent's transition applies to its children", input: "sc", expectedState: "idle"},
+		{name: "a child's transition overrides its parent's", input: "slc", expectedState: "confirming"},
+		{name: "a grandparent's transition applies to grandchildren", input: "slcc", expectedState: "idle"},
+		{name: "transitions from a child", input: "slcn", expectedState: "running"},
+		{name: "a self transition on a parent re-enters its initial child", input: "slcr", expectedState: "running"},
+		{name: "ignored events", input: "sfyn", expectedState: "loading"},
+		{name: "leaving the hierarchy", input: "slcyslf", expectedState: "done"},
+	}
+
+	for _, currentTest := range tests {
+		t.Run(currentTest.name, func(t *testing.T) {
+			run := machine.NewRun()
+			assert.Nil(t, run.Feed(currentTest.input))
+			assert.Equal(t, currentTest.expectedState, run.State())
+		})
+	}
+
+	_, accepted := machine.Process("slf")
+	assert.True(t, accepted)
+	_, accepted = machine.Process("sl")
+	assert.False(t, accepted)
+}
+
+func TestFlattenInitialChild(t *testing.T) {
+	chart := New().
+		Child("door", "closed", "open").
+		InitialChild("door", "open").
+		Initial("door").
+		Final("closed").
+		On("open", "c", "closed").
+		On("closed", "o", "open").
+		On("door", "r", "door")
+
+	machine := flattenedMachine(t, chart)
+	assert.Equal(t, "open", machine.Config.InitialState())
+
+	state, accepted := machine.Process("cocr")
+	assert.False(t, accepted)
+	assert.Nil(t, state)
+
+	state, accepted = machine.Process("rc")
+	assert.True(t, accepted)
+	assert.Equal(t, "closed", *state)
+}
+
+func TestFlattenFinalParent(t *testing.T) {
+	chart := New().
+		Child("finished", "succeeded", "failed").
+		Initial("working").
+		Final("finished").
+		On("working", "s", "succeeded").
+		On("working", "f", "failed")
+
+	machine := flattenedMachine(t, chart)
+	for input, expected := range map[string]bool{"s": true, "f": true, "x": false} {
+		_, accepted := machine.Process(input)
+		assert.Equal(t, expected, accepted, input)
+	}
+}
+
+func TestFlattenValidation(t *testing.T) {
+	type test struct {
+		name          string
+		chart         *Chart
+		expectedError []error
+	}
+
+	tests := []test{
+		{
+			name:          "no initial state",
+			chart:         New().Final("a").On("a", "x", "a"),
+			expectedError: []error{ErrNoInitialState},
+		},
+		{
+			name: "problems while building",
+			chart: New().
+				Initial("a").
+				Initial("b").
+				Child("a", "b", "c,d").
+				Child("x", "b").
+				On("a", "", "b").
+				On("a", "x", "b").
+				On("a", "x", "c,d").
+				InitialChild("x", "c,d"),
+			expectedError: []error{fsm.ErrInvalidInitialState, ErrInvalidStateName, ErrDuplicateParent, ErrNoEvents, ErrDuplicateTransition, ErrInvalidInitialChild},
+		},
+		{
+			name:          "cycle",
+			chart:         New().Initial("a").Child("a", "b").Child("b", "a").Final("a").On("a", "x", "b"),
+			expectedError: []error{ErrHierarchyCycle},
+		},
+		{
+			name:          "nothing final",
+			chart:         New().Initial("a").On("a", "x", "a"),
+			expectedError: []error{fsm.ErrEmptyFinalStates},
+		},
+	}
+
+	for _, currentTest := range tests {
+		t.Run(currentTest.name, func(t *testing.T) {
+			_, err := currentTest.chart.Flatten()
+			for _, expected := range currentTest.expectedError {
+				assert.ErrorIs(t, err, expected)
+			}
+		})
+	}
+}
+
+// newEditorChart is a text editor with bold and italic that toggle independently while editing, and x clears both,
+// until it is closed. Closing is ignored while bold is on.
+func newEditorChart() *Chart {
+	return New().
+		Parallel("editing", "bold", "italic").
+		Child("bold", "plain", "bolded").
+		Child("italic", "upright", "slanted").
+		State("closed").
+		Initial("editing").
+		Final("plain", "upright", "closed").
+		On("plain", "b", "bolded").
+		On("bolded", "b", "plain").
+		On("upright", "i", "slanted").
+		On("slanted", "i", "upright").
+		On("bolded", "x", "plain").
+		On("slanted", "x", "upright").
+		On("editing", "q", "closed").
+		On("bolded", "q", "bolded")
+}
+
+func TestFlattenParallel(t *testing.T) {
+	machine := flattenedMachine(t, newEditorChart())
+	assert.Equal(t, "plain,upright", machine.Config.InitialState())
+	assert.Equal(t, []string{"bolded,slanted", "bolded,upright", "closed", "plain,slanted", "plain,upright"}, machine.Config.States())
+
+	type test struct {
+		name          string
+		input         string
+		expectedState string
+	}
+
+	tests := []test{
+		{name: "regions advance independently", input: "b", expectedState: "bolded,upright"},
+		{name: "both regions", input: "bi", expectedState: "bolded,slanted"},
+		{name: "an event every region handles moves them all", input: "bix", expectedState: "plain,upright"},
+		{name: "an event only some regions handle", input: "bx", expectedState: "plain,upright"},
+		{name: "a transition on the parallel state leaves every region", input: "iq", expectedState: "closed"},
+		{name: "a region's own transition wins over the parallel state's", input: "bq", expectedState: "bolded,upright"},
+		{name: "ignored events", input: "xq", expectedState: "closed"},
+	}
+
+	for _, currentTest := range tests {
+		t.Run(currentTest.name, func(t *testing.T) {
+			run := machine.NewRun()
+			assert.Nil(t, run.Feed(currentTest.input))
+			assert.Equal(t, currentTest.expectedState, run.State())
+		})
+	}
+
+	// final once every region is in a final state
+	for input, expected := range map[string]bool{"bb": true, "b": false, "i": false, "bibi": true, "iq": true} {
+		_, accepted := machine.Process(input)
+		assert.Equal(t, expected, accepted, input)
+	}
+}
+
+func TestFlattenParallelEntry(t *testing.T) {
+	// entering one region directly enters the others by default, and moving between regions re-enters them all
+	chart := New().
+		State("off").
+		Parallel("on", "left", "right").
+		Child("left", "l1", "l2").
+		Child("right", "r1", "r2").
+		Initial("off").
+		Final("off").
+		On("off", "a", "r2").
+		On("l1", "n", "l2").
+		On("r1", "n", "r2").
+		On("l2", "j", "r1").
+		On("on", "o", "off")
+
+	machine := flattenedMachine(t, chart)
+	for input, expected := range map[string]string{
+		"a":   "l1,r2",
+		"an":  "l2,r2",
+		"anj": "l1,r1",
+		"ano": "off",
+	} {
+		run := machine.NewRun()
+		assert.Nil(t, run.Feed(input))
+		assert.Equal(t, expected, run.State(), input)
+	}
+
+	_, err := New().Parallel("p", "a", "b").InitialChild("p", "b").Initial("p").Final("a").On("a", "x", "b").Flatten()
+	assert.ErrorIs(t, err, ErrInvalidInitialChild)
+}
@@ -0,0 +1,124 @@
+package statechart
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newPausableChart is a job that fetches, then parses and writes, and can be paused at any point and resumed
+// through the history state, which is shallow or deep depending on history
+func newPausableChart(history func(c *Chart, parent string, name string) *Chart) *Chart {
+	chart := New().
+		Child("job", "fetching", "processing").
+		Child("processing", "parsing", "writing").
+		State("paused", "done").
+		Initial("job").
+		Final("done").
+		On("fetching", "n", "processing").
+		On("parsing", "n", "writing").
+		On("writing", "n", "done").
+		On("job", "p", "paused").
+		On("paused", "r", "resume").
+		On("paused", "x", "job")
+	return history(chart, "job", "resume")
+}
+
+func TestFlattenHistory(t *testing.T) {
+	type test struct {
+		name          string
+		history       func(c *Chart, parent string, name string) *Chart
+		input         string
+		expectedState string
+	}
+
+	tests := []test{
+		{name: "shallow history before anything is recorded", history: (*Chart).ShallowHistory, input: "pr", expectedState: "fetching[resume=fetching]"},
+		{name: "shallow history resumes the child", history: (*Chart).ShallowHistory, input: "npr", expectedState: "parsing[resume=processing]"},
+		{name: "shallow history enters the child's initial child", history: (*Chart).ShallowHistory, input: "nnpr", expectedState: "parsing[resume=processing]"},
+		{name: "deep history resumes all the way down", history: (*Chart).DeepHistory, input: "nnpr", expectedState: "writing[resume=writing]"},
+		{name: "entering the parent ignores history", history: (*Chart).DeepHistory, input: "nnpx", expectedState: "fetching[resume=writing]"},
+		{name: "history is recorded again on each exit", history: (*Chart).DeepHistory, input: "nnpxpr", expectedState: "fetching[resume=fetching]"},
+		{name: "the recording is part of the state", history: (*Chart).DeepHistory, input: "np", expectedState: "paused[resume=parsing]"},
+	}
+
+	for _, currentTest := range tests {
+		t.Run(currentTest.name, func(t *testing.T) {
+			machine := flattenedMachine(t, newPausableChart(currentTest.history))
+			run := machine.NewRun()
+			assert.Nil(t, run.Feed(currentTest.input))
+			assert.Equal(t, currentTest.expectedState, run.State())
+		})
+	}
+
+	// without history the pause would lose progress, so with it "nnprn" finishes the job
+	machine := flattenedMachine(t, newPausableChart((*Chart).DeepHistory))
+	_, accepted := machine.Process("nnprn")
+	assert.True(t, accepted)
+	assert.Equal(t, "fetching", machine.Config.InitialState())
+}
+
+func TestFlattenParallelHistory(t *testing.T) {
+	type test struct {
+		name          string
+		history       func(c *Chart, parent string, name string) *Chart
+		expectedState string
+	}
+
+	tests := []test{
+		{name: "shallow", history: (*Chart).ShallowHistory, expectedState: "plain,upright[back=bold,italic]"},
+		{name: "deep", history: (*Chart).DeepHistory, expectedState: "bolded,slanted[back=bolded,slanted]"},
+	}
+
+	for _, currentTest := range tests {
+		t.Run(currentTest.name, func(t *testing.T) {
+			chart := currentTest.history(newEditorChart(), "editing", "back").
+				On("editing", "c", "closed").
+				On("closed", "o", "back")
+			machine := flattenedMachine(t, chart)
+			run := machine.NewRun()
+			// shallow history only remembers that both regions were active, so they start over
+			assert.Nil(t, run.Feed("bico"))
+			assert.Equal(t, currentTest.expectedState, run.State())
+		})
+	}
+}
+
+func TestHistoryValidation(t *testing.T) {
+	type test struct {
+		name  string
+		chart *Chart
+	}
+
+	tests := []test{
+		{name: "transition from a history state", chart: New().Child("a", "b").ShallowHistory("a", "h").Initial("a").Final("b").On("h", "x", "b")},
+		{name: "history state with children", chart: New().Child("a", "b").DeepHistory("a", "h").Child("h", "c").Initial("a").Final("b").On("b", "x", "h")},
+		{name: "final history state", chart: New().Child("a", "b").DeepHistory("a", "h").Initial("a").Final("h").On("b", "x", "h")},
+		{name: "history state outside a composite state", chart: New().DeepHistory("a", "h").Initial("a").Final("a").On("a", "x", "h")},
+	}
+
+	for _, currentTest := range tests {
+		t.Run(currentTest.name, func(t *testing.T) {
+			_, err := currentTest.chart.Flatten()
+			assert.ErrorIs(t, err, ErrInvalidHistory)
+		})
+	}
+
+	_, err := New().Child("a", "b").DeepHistory("a", "h").DeepHistory("c", "h").Initial("a").Final("b").On("b", "x", "h").Flatten()
+	assert.ErrorIs(t, err, ErrDuplicateParent)
+
+	_, err = New().Child("a", "b").ShallowHistory("a", "h").InitialChild("a", "h").Initial("a").Final("b").On("b", "x", "h").Flatten()
+	assert.ErrorIs(t, err, ErrInvalidInitialChild)
+	assert.ErrorContains(t, err, "h is a history state")
+
+	_, err = New().Child("a", "b", "h").DeepHistory("a", "h").Initial("a").Final("b").On("b", "x", "h").Flatten()
+	assert.ErrorIs(t, err, ErrInvalidHistory)
+	assert.ErrorContains(t, err, "h is already a child of a")
+
+	_, err = New().DeepHistory("a", "h").Child("a", "b", "h").Initial("a").Final("b").On("b", "x", "h").Flatten()
+	assert.ErrorIs(t, err, ErrInvalidHistory)
+	assert.ErrorContains(t, err, "h is a history state, not a child of a")
+
+	_, err = New().Initial("a[0]").Final("a[0]").On("a[0]", "x", "a[0]").Flatten()
+	assert.ErrorIs(t, err, ErrInvalidStateName)
+}